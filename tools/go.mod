@@ -3,3 +3,8 @@ module github.com/publicsuffix/list/tools
 go 1.21
 
 require github.com/google/go-cmp v0.6.0
+
+require (
+	golang.org/x/net v0.35.0
+	golang.org/x/text v0.22.0
+)
@@ -12,6 +12,7 @@ import (
 
 func main() {
 	warnings := flag.Bool("with-warnings", false, "also print errors that were downgraded to warnings")
+	maxErrors := flag.Int("max-errors", 0, "stop parsing after this many errors (0 means no limit)")
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] pslfile\n", os.Args[0])
 		flag.PrintDefaults()
@@ -30,7 +31,9 @@ func main() {
 		os.Exit(1)
 	}
 
-	psl := parser.Parse(string(bs))
+	psl := parser.ParseWithOptions(string(bs), parser.ParseOptions{
+		MaxErrors: *maxErrors,
+	})
 
 	for _, err := range psl.Errors {
 		fmt.Println(err)
@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"time"
+)
+
+// BlameEntry is the version control history of one line of a PSL
+// file, as reported by a tool such as "git blame".
+type BlameEntry struct {
+	Author     string
+	CommitHash string
+	CommitDate time.Time
+}
+
+// BlameInfo maps line numbers of a PSL file to their BlameEntry. The
+// caller computes it from the same revision of the file that was
+// parsed, for example with "git blame --porcelain".
+type BlameInfo map[int]BlameEntry
+
+// Lookup returns the most recent BlameEntry for the lines of s. For a
+// suffix block, this is the last change to any of its lines, and so
+// identifies who last touched the block.
+//
+// Lookup returns false if b has no entry for any line of s.
+func (b BlameInfo) Lookup(s Source) (BlameEntry, bool) {
+	var (
+		ret   BlameEntry
+		found bool
+	)
+	for line := s.StartLine; line <= s.EndLine; line++ {
+		entry, ok := b[line]
+		if !ok {
+			continue
+		}
+		if !found || entry.CommitDate.After(ret.CommitDate) {
+			ret = entry
+			found = true
+		}
+	}
+	return ret, found
+}
@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+func TestBlameInfoLookup(t *testing.T) {
+	f := Parse(dedent(`
+      // Example Corp
+      // Submitted by Example <example@example.com>
+      a.example.com
+      b.example.com
+    `))
+	block := f.AllSuffixBlocks()[0]
+
+	old := BlameEntry{
+		Author:     "Original Author",
+		CommitHash: "1111111",
+		CommitDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	recent := BlameEntry{
+		Author:     "Recent Editor",
+		CommitHash: "2222222",
+		CommitDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	blame := BlameInfo{1: old, 2: old, 3: old, 4: recent}
+
+	got, ok := blame.Lookup(block.Source)
+	if !ok {
+		t.Fatal("Lookup(block) found no entry")
+	}
+	if diff := diff.Diff(recent, got); diff != "" {
+		t.Errorf("Lookup(block) wrong result (-want +got):\n%s", diff)
+	}
+
+	got, ok = blame.Lookup(block.Entries[0])
+	if !ok {
+		t.Fatal("Lookup(entry) found no entry")
+	}
+	if diff := diff.Diff(old, got); diff != "" {
+		t.Errorf("Lookup(entry) wrong result (-want +got):\n%s", diff)
+	}
+
+	if _, ok := blame.Lookup(src(10, 12, "")); ok {
+		t.Error("Lookup of lines with no blame info returned an entry")
+	}
+}
@@ -0,0 +1,313 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Marshal returns the PSL source text for f.
+//
+// Each block's source text is written starting at the line given by
+// its StartLine, and any gaps between blocks are filled with empty
+// lines. For a File produced by Parse, this reproduces the parsed
+// text, except for whitespace at the start and end of lines, which
+// the parser discards.
+func (f *File) Marshal() []byte {
+	var ret strings.Builder
+
+	prevLine := 1
+	for _, block := range f.Blocks {
+		src := block.source()
+		for prevLine < src.StartLine {
+			ret.WriteByte('\n')
+			prevLine++
+		}
+		ret.WriteString(src.Raw)
+		ret.WriteByte('\n')
+		prevLine = src.EndLine + 1
+	}
+
+	return []byte(ret.String())
+}
+
+// The Add* methods below build a File programmatically, by appending
+// blocks to the end of f.Blocks. Each block is given source text and
+// line numbers in the canonical PSL format, so that the result can be
+// written out with Marshal.
+//
+// The builders do not validate their inputs. To check that the
+// result is a valid PSL file, parse the output of Marshal.
+
+// AddComment appends a top-level comment block to f, with one "// "
+// comment line for each element of lines.
+func (f *File) AddComment(lines ...string) Comment {
+	start := f.nextBlockLine()
+	var comments []string
+	for _, line := range lines {
+		comments = append(comments, strings.TrimSpace("// "+line))
+	}
+	ret := Comment{
+		Source: Source{
+			StartLine: start,
+			EndLine:   start + len(comments) - 1,
+			Raw:       strings.Join(comments, "\n"),
+		},
+	}
+	f.Blocks = append(f.Blocks, ret)
+	return ret
+}
+
+// AddSectionStart appends a marker to f that starts a file section
+// with the given name, e.g. "ICANN DOMAINS".
+//
+// As in the canonical PSL, a section start that follows the end of
+// another section is placed on the line immediately after it.
+func (f *File) AddSectionStart(name string) StartSection {
+	start := f.nextBlockLine()
+	if n := len(f.Blocks); n > 0 {
+		if _, ok := f.Blocks[n-1].(EndSection); ok {
+			start--
+		}
+	}
+	ret := StartSection{
+		Source: Source{
+			StartLine: start,
+			EndLine:   start,
+			Raw:       sectionMarker + "BEGIN " + name + "===",
+		},
+		Name: name,
+	}
+	f.Blocks = append(f.Blocks, ret)
+	return ret
+}
+
+// AddSectionEnd appends a marker to f that ends the file section
+// with the given name.
+func (f *File) AddSectionEnd(name string) EndSection {
+	start := f.nextBlockLine()
+	ret := EndSection{
+		Source: Source{
+			StartLine: start,
+			EndLine:   start,
+			Raw:       sectionMarker + "END " + name + "===",
+		},
+		Name: name,
+	}
+	f.Blocks = append(f.Blocks, ret)
+	return ret
+}
+
+// AddSuffixBlock appends a block of suffixes to f, with a canonical
+// header built from entity, u and submitter:
+//
+//	// <entity> : <u>
+//	// Submitted by <submitter>
+//
+// u and submitter may be nil, in which case the corresponding header
+// information is omitted.
+func (f *File) AddSuffixBlock(entity string, u *url.URL, submitter *mail.Address, suffixes ...string) Suffixes {
+	var header []string
+	if u != nil {
+		header = append(header, fmt.Sprintf("// %s : %s", entity, u))
+	} else {
+		header = append(header, "// "+entity)
+	}
+	if submitter != nil {
+		header = append(header, "// Submitted by "+formatSubmitter(submitter))
+	}
+
+	ret := suffixesFromLines(f.nextBlockLine(), append(header, suffixes...))
+	f.Blocks = append(f.Blocks, ret)
+	return ret
+}
+
+// suffixesFromLines returns a suffix block starting at line start,
+// with the given lines of text. As in the parser, comment lines
+// before the first suffix entry are the block's header, and later
+// comment lines are inline comments.
+func suffixesFromLines(start int, lines []string) Suffixes {
+	ret := Suffixes{}
+	for i, line := range lines {
+		src := Source{StartLine: start + i, EndLine: start + i, Raw: line}
+		switch {
+		case !strings.HasPrefix(line, "//"):
+			ret.Entries = append(ret.Entries, src)
+		case len(ret.Entries) > 0:
+			ret.InlineComments = append(ret.InlineComments, src)
+		default:
+			ret.Header = append(ret.Header, src)
+		}
+	}
+	ret.Source = Source{
+		StartLine: start,
+		EndLine:   start + len(lines) - 1,
+		Raw:       strings.Join(lines, "\n"),
+	}
+
+	// Extract metadata the same way the parser does, so that the
+	// block is identical to what parsing its text would produce.
+	var p parser
+	p.enrichSuffixes(&ret)
+
+	return ret
+}
+
+// SuffixBlockSpec describes a block of suffixes for AddSection.
+type SuffixBlockSpec struct {
+	Entity    string
+	URL       *url.URL
+	Submitter *mail.Address
+	Suffixes  []string
+}
+
+// AddSection appends a complete file section to f: a start marker, a
+// suffix block for each element of blocks, and an end marker.
+//
+// The section is written in canonical order. Blocks are sorted by
+// entity name, ignoring case. Suffixes within each block are sorted
+// by their labels from right to left, so that subdomains follow their
+// parent domain, and exceptions directly follow their wildcard. blocks
+// itself is not modified.
+func (f *File) AddSection(name string, blocks []SuffixBlockSpec) {
+	blocks = slices.Clone(blocks)
+	slices.SortStableFunc(blocks, func(a, b SuffixBlockSpec) int {
+		return strings.Compare(entitySortKey(a.Entity), entitySortKey(b.Entity))
+	})
+
+	f.AddSectionStart(name)
+	for _, block := range blocks {
+		suffixes := slices.Clone(block.Suffixes)
+		slices.SortStableFunc(suffixes, func(a, b string) int {
+			return slices.Compare(suffixSortKey(a), suffixSortKey(b))
+		})
+		f.AddSuffixBlock(block.Entity, block.URL, block.Submitter, suffixes...)
+	}
+	f.AddSectionEnd(name)
+}
+
+// suffixSortKey returns the sort key of a suffix entry for
+// AddSection: its labels from right to left, with a wildcard rule
+// sorting before the subdomains of its parent, and exceptions
+// sorting directly after their wildcard.
+func suffixSortKey(entry string) []string {
+	prefix, domain := splitRulePrefix(strings.ToLower(entry))
+	labels := strings.Split(domain, ".")
+	slices.Reverse(labels)
+	switch prefix {
+	case "*.":
+		labels = append(labels, "*")
+	case "!":
+		// "!a.example.com" sorts as if it were "a.*.example.com".
+		last := len(labels) - 1
+		labels = append(labels[:last], "*", labels[last])
+	}
+	return labels
+}
+
+// ErrNotSuffixBlock is returned by AddSuffix when the last block of
+// the File is not a suffix block.
+var ErrNotSuffixBlock = errors.New("last block is not a suffix block")
+
+// AddSuffix appends suffix to the last block in f, which must be a
+// Suffixes block such as one created by AddSuffixBlock.
+func (f *File) AddSuffix(suffix string) error {
+	n := len(f.Blocks)
+	if n == 0 {
+		return ErrNotSuffixBlock
+	}
+	block, ok := f.Blocks[n-1].(Suffixes)
+	if !ok {
+		return ErrNotSuffixBlock
+	}
+
+	line := block.EndLine + 1
+	block.Entries = append(block.Entries, Source{StartLine: line, EndLine: line, Raw: suffix})
+	block.EndLine = line
+	block.Raw += "\n" + suffix
+	f.Blocks[n-1] = block
+	return nil
+}
+
+// nextBlockLine returns the line on which a new block appended to f
+// should start, leaving one empty line after the last block.
+func (f *File) nextBlockLine() int {
+	n := len(f.Blocks)
+	if n == 0 {
+		return 1
+	}
+	return f.Blocks[n-1].source().EndLine + 2
+}
+
+// RenumberLines rewrites the line numbers of all blocks in f to
+// follow the canonical layout used by the File builders: blocks are
+// separated by one empty line, except that a section start directly
+// follows the end of the preceding section. Block text is not
+// changed.
+//
+// Call RenumberLines after rearranging f.Blocks directly, so that
+// Marshal and LocationString produce correct results. Line numbers in
+// f.Errors and f.Warnings are not updated.
+func (f *File) RenumberLines() {
+	blocks := f.Blocks
+	f.Blocks = make([]Block, 0, len(blocks))
+	for _, block := range blocks {
+		start := f.nextBlockLine()
+		if _, ok := block.(StartSection); ok && len(f.Blocks) > 0 {
+			if _, ok := f.Blocks[len(f.Blocks)-1].(EndSection); ok {
+				start--
+			}
+		}
+		f.Blocks = append(f.Blocks, moveBlock(block, start))
+	}
+}
+
+// moveBlock returns a copy of b with its source moved to start at
+// line start.
+func moveBlock(b Block, start int) Block {
+	delta := start - b.source().StartLine
+	shift := func(s Source) Source {
+		s.StartLine += delta
+		s.EndLine += delta
+		return s
+	}
+	shiftAll := func(srcs []Source) {
+		for i := range srcs {
+			srcs[i] = shift(srcs[i])
+		}
+	}
+
+	switch v := b.(type) {
+	case Comment:
+		v.Source = shift(v.Source)
+		return v
+	case StartSection:
+		v.Source = shift(v.Source)
+		return v
+	case EndSection:
+		v.Source = shift(v.Source)
+		return v
+	case Suffixes:
+		v = v.Clone()
+		v.Source = shift(v.Source)
+		shiftAll(v.Header)
+		shiftAll(v.Entries)
+		shiftAll(v.InlineComments)
+		return v
+	default:
+		panic(fmt.Sprintf("unknown block type %T", b))
+	}
+}
+
+// formatSubmitter formats addr in the conventional PSL form "Name
+// <email>". Unlike mail.Address.String, names are never quoted or
+// encoded.
+func formatSubmitter(addr *mail.Address) string {
+	if addr.Name == "" {
+		return "<" + addr.Address + ">"
+	}
+	return addr.Name + " <" + addr.Address + ">"
+}
@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+func TestBuildAndMarshal(t *testing.T) {
+	var f File
+	f.AddComment("Example PSL file.", "", "Generated by a test.")
+	f.AddSectionStart("ICANN DOMAINS")
+	f.AddSuffixBlock("com", mustURL("https://www.iana.org/domains/root/db/com.html"), nil, "com")
+	f.AddSectionEnd("ICANN DOMAINS")
+	f.AddSectionStart("PRIVATE DOMAINS")
+	f.AddSuffixBlock("DuckCorp Inc", mustURL("https://example.com"), mustEmail("Not A Duck <duck@example.com>"), "duck.example.com")
+	if err := f.AddSuffix("*.quack.example.com"); err != nil {
+		t.Fatalf("AddSuffix failed: %v", err)
+	}
+	f.AddSectionEnd("PRIVATE DOMAINS")
+
+	want := dedent(`
+      // Example PSL file.
+      //
+      // Generated by a test.
+
+      // ===BEGIN ICANN DOMAINS===
+
+      // com : https://www.iana.org/domains/root/db/com.html
+      com
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // DuckCorp Inc : https://example.com
+      // Submitted by Not A Duck <duck@example.com>
+      duck.example.com
+      *.quack.example.com
+
+      // ===END PRIVATE DOMAINS===
+    `) + "\n"
+	got := string(f.Marshal())
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected Marshal output (-want +got):\n%s", diff)
+	}
+
+	parsed := Parse(got)
+	if len(parsed.Errors) > 0 {
+		t.Fatalf("built file has parse errors: %v", parsed.Errors)
+	}
+	if diff := diff.Diff(f.Blocks, parsed.Blocks); diff != "" {
+		t.Errorf("built blocks differ from parsed blocks (-built +parsed):\n%s", diff)
+	}
+}
+
+func TestAddSection(t *testing.T) {
+	specs := []SuffixBlockSpec{
+		{
+			Entity:    "Zebra Corp",
+			Submitter: mustEmail("Zebra <zebra@example.com>"),
+			Suffixes:  []string{"zebra.example"},
+		},
+		{
+			Entity:    "aardvark Inc",
+			URL:       mustURL("https://aardvark.example"),
+			Submitter: mustEmail("Aardvark <aardvark@example.com>"),
+			Suffixes: []string{
+				"b.aardvark.example",
+				"!www.aardvark.example",
+				"aardvark.example",
+				"*.aardvark.example",
+				"!api.aardvark.example",
+				"a.aardvark.example",
+			},
+		},
+	}
+	orig := append([]string(nil), specs[1].Suffixes...)
+
+	var f File
+	f.AddSection("PRIVATE DOMAINS", specs)
+
+	want := dedent(`
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // aardvark Inc : https://aardvark.example
+      // Submitted by Aardvark <aardvark@example.com>
+      aardvark.example
+      *.aardvark.example
+      !api.aardvark.example
+      !www.aardvark.example
+      a.aardvark.example
+      b.aardvark.example
+
+      // Zebra Corp
+      // Submitted by Zebra <zebra@example.com>
+      zebra.example
+
+      // ===END PRIVATE DOMAINS===
+    `) + "\n"
+	got := string(f.Marshal())
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected Marshal output (-want +got):\n%s", diff)
+	}
+
+	if diff := diff.Diff(orig, specs[1].Suffixes); diff != "" {
+		t.Errorf("AddSection modified its input (-want +got):\n%s", diff)
+	}
+
+	parsed := Parse(got)
+	if len(parsed.Errors) > 0 {
+		t.Errorf("built file has parse errors: %v", parsed.Errors)
+	}
+	if errs := parsed.DetectUnsortedExceptions(); len(errs) > 0 {
+		t.Errorf("built file has unsorted exceptions: %v", errs)
+	}
+}
+
+func TestRenumberLines(t *testing.T) {
+	f := Parse("// Top comment.\n\n\n\n" + testFile)
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	// Swap the first two private suffix blocks, and drop the extra
+	// empty lines after the top comment.
+	f.Blocks[5], f.Blocks[6] = f.Blocks[6], f.Blocks[5]
+	f.RenumberLines()
+
+	got := string(f.Marshal())
+	want := "// Top comment.\n\n" + strings.Replace(testFile,
+		"// AWS Thing : https://aws.amazon.com/thing\n// Submitted by AWS Security <psl-maintainers@amazon.com>\nthing.example\n\n// AWS Other Thing : https://aws.amazon.com/other\n// Submitted by AWS Security <psl-maintainers@amazon.com>\nother.example",
+		"// AWS Other Thing : https://aws.amazon.com/other\n// Submitted by AWS Security <psl-maintainers@amazon.com>\nother.example\n\n// AWS Thing : https://aws.amazon.com/thing\n// Submitted by AWS Security <psl-maintainers@amazon.com>\nthing.example",
+		1) + "\n"
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected Marshal output (-want +got):\n%s", diff)
+	}
+
+	parsed := Parse(got)
+	if diff := diff.Diff(parsed.Blocks, f.Blocks); diff != "" {
+		t.Errorf("renumbered blocks differ from parsed blocks (-parsed +renumbered):\n%s", diff)
+	}
+}
+
+func TestAddSuffixWithoutBlock(t *testing.T) {
+	var f File
+	if err := f.AddSuffix("example.com"); err != ErrNotSuffixBlock {
+		t.Errorf("AddSuffix on empty file returned %v, want ErrNotSuffixBlock", err)
+	}
+	f.AddComment("Not a suffix block.")
+	if err := f.AddSuffix("example.com"); err != ErrNotSuffixBlock {
+		t.Errorf("AddSuffix after comment returned %v, want ErrNotSuffixBlock", err)
+	}
+}
+
+// TestMarshalRealList checks that marshaling the parsed real list
+// reproduces the original file.
+func TestMarshalRealList(t *testing.T) {
+	bs, err := os.ReadFile("../../../public_suffix_list.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := Parse(string(bs))
+
+	got := strings.Split(strings.TrimSpace(string(f.Marshal())), "\n")
+	want := strings.Split(strings.TrimSpace(string(bs)), "\n")
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("Marshal did not reproduce the original file (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// ContactInfo is the contact information for one entity in a PSL
+// file, aggregated across all of the entity's suffix blocks.
+type ContactInfo struct {
+	// Entity is the entity name.
+	Entity string
+	// Emails are the distinct submitter addresses of the entity's
+	// blocks, in the order they first appear.
+	Emails []*mail.Address
+	// URLs are the distinct URLs of the entity's blocks, in the
+	// order they first appear.
+	URLs []*url.URL
+	// Section is the name of the file section containing the
+	// entity's first block.
+	Section string
+	// SuffixCount is the total number of suffix entries in the
+	// entity's blocks.
+	SuffixCount int
+}
+
+// ExtractContactDirectory returns the contact information of every
+// entity in f, sorted by entity name in the same case-insensitive
+// order as ValidateOrdering. Blocks with the same entity name are
+// merged into a single ContactInfo. Blocks without an entity name are
+// skipped.
+func (f *File) ExtractContactDirectory() []ContactInfo {
+	var ret []ContactInfo
+
+	byEntity := map[string]int{} // entity name to index in ret
+	f.forEachSuffixBlock(func(section string, block Suffixes) {
+		if block.Entity == "" {
+			return
+		}
+		idx, ok := byEntity[block.Entity]
+		if !ok {
+			idx = len(ret)
+			byEntity[block.Entity] = idx
+			ret = append(ret, ContactInfo{
+				Entity:  block.Entity,
+				Section: section,
+			})
+		}
+		info := &ret[idx]
+
+		info.SuffixCount += len(block.Entries)
+		if block.Submitter != nil && !slices.ContainsFunc(info.Emails, func(a *mail.Address) bool {
+			return strings.EqualFold(a.Address, block.Submitter.Address)
+		}) {
+			info.Emails = append(info.Emails, block.Submitter)
+		}
+		if block.URL != nil && !slices.ContainsFunc(info.URLs, func(u *url.URL) bool {
+			return u.String() == block.URL.String()
+		}) {
+			info.URLs = append(info.URLs, block.URL)
+		}
+	})
+
+	slices.SortStableFunc(ret, func(a, b ContactInfo) int {
+		return strings.Compare(entitySortKey(a.Entity), entitySortKey(b.Entity))
+	})
+	return ret
+}
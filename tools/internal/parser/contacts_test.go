@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"net/mail"
+	"net/url"
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+func TestExtractContactDirectory(t *testing.T) {
+	f := Parse(testFile + "\n\n" + dedent(`
+      // AWS Thing : https://aws.amazon.com/thing2
+      // Submitted by Other AWS <PSL-Maintainers@amazon.com>
+      a.thing.example
+      b.thing.example
+
+      // DuckCorp Inc : https://duck.example.org/
+      // Submitted by Quack <quack@example.com>
+      quack.example
+
+      noentity.example
+    `))
+
+	want := []ContactInfo{
+		{
+			Entity:      "AWS Other Thing",
+			Emails:      []*mail.Address{mustEmail("AWS Security <psl-maintainers@amazon.com>")},
+			URLs:        []*url.URL{mustURL("https://aws.amazon.com/other")},
+			Section:     "PRIVATE DOMAINS",
+			SuffixCount: 1,
+		},
+		{
+			Entity:      "AWS Thing",
+			Emails:      []*mail.Address{mustEmail("AWS Security <psl-maintainers@amazon.com>")},
+			URLs:        []*url.URL{mustURL("https://aws.amazon.com/thing"), mustURL("https://aws.amazon.com/thing2")},
+			Section:     "PRIVATE DOMAINS",
+			SuffixCount: 3,
+		},
+		{
+			Entity:      "DuckCorp Inc",
+			Emails:      []*mail.Address{mustEmail("Not A Duck <duck@example.com>"), mustEmail("Quack <quack@example.com>")},
+			URLs:        []*url.URL{mustURL("https://duck.example.org/")},
+			Section:     "PRIVATE DOMAINS",
+			SuffixCount: 2,
+		},
+		{
+			Entity:      "example",
+			URLs:        []*url.URL{mustURL("https://www.iana.org/domains/root/db/example.html")},
+			Section:     "ICANN DOMAINS",
+			SuffixCount: 1,
+		},
+		{
+			Entity:      "No URL Corp",
+			Emails:      []*mail.Address{mustEmail("Nobody <nobody@example.com>")},
+			Section:     "PRIVATE DOMAINS",
+			SuffixCount: 1,
+		},
+	}
+	got := f.ExtractContactDirectory()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected contact directory (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,238 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// EditOp is one step of an EditScript. It is one of InsertBlock,
+// RemoveBlock, MoveBlock or ModifyBlock.
+//
+// Block indexes in an EditOp refer to the list of blocks as it is
+// when the operation is applied, after all preceding operations of
+// the script.
+type EditOp interface {
+	apply(blocks []Block) ([]Block, error)
+}
+
+// InsertBlock inserts Block at Index in the list of blocks.
+type InsertBlock struct {
+	Index int
+	Block Block
+}
+
+// RemoveBlock removes the block at Index.
+type RemoveBlock struct {
+	Index int
+}
+
+// MoveBlock moves the block at From so that it ends up at index To.
+type MoveBlock struct {
+	From int
+	To   int
+}
+
+// ModifyBlock replaces the block at Index with Block.
+type ModifyBlock struct {
+	Index int
+	Block Block
+}
+
+func (op InsertBlock) apply(blocks []Block) ([]Block, error) {
+	if op.Index < 0 || op.Index > len(blocks) {
+		return nil, fmt.Errorf("insert at index %d out of range [0,%d]", op.Index, len(blocks))
+	}
+	return slices.Insert(blocks, op.Index, op.Block), nil
+}
+
+func (op RemoveBlock) apply(blocks []Block) ([]Block, error) {
+	if op.Index < 0 || op.Index >= len(blocks) {
+		return nil, fmt.Errorf("remove at index %d out of range [0,%d)", op.Index, len(blocks))
+	}
+	return slices.Delete(blocks, op.Index, op.Index+1), nil
+}
+
+func (op MoveBlock) apply(blocks []Block) ([]Block, error) {
+	if op.From < 0 || op.From >= len(blocks) || op.To < 0 || op.To >= len(blocks) {
+		return nil, fmt.Errorf("move from index %d to %d out of range [0,%d)", op.From, op.To, len(blocks))
+	}
+	b := blocks[op.From]
+	blocks = slices.Delete(blocks, op.From, op.From+1)
+	return slices.Insert(blocks, op.To, b), nil
+}
+
+func (op ModifyBlock) apply(blocks []Block) ([]Block, error) {
+	if op.Index < 0 || op.Index >= len(blocks) {
+		return nil, fmt.Errorf("modify at index %d out of range [0,%d)", op.Index, len(blocks))
+	}
+	blocks[op.Index] = op.Block
+	return blocks, nil
+}
+
+// EditScript is a sequence of edit operations that transforms the
+// blocks of one File into those of another.
+type EditScript []EditOp
+
+// GenerateEditScript returns an edit script that transforms f into
+// target.
+//
+// Blocks of f and target are paired up when their source text is
+// identical, or failing that, when they are suffix blocks with the
+// same entity name. Unpaired blocks of f are removed, and unpaired
+// blocks of target are inserted. Paired suffix blocks whose text
+// differs are modified. The script moves the fewest possible blocks:
+// only paired blocks that are out of order relative to the others
+// are moved.
+//
+// Returns an error if f or target has parse errors, since their
+// blocks may not reflect their source text.
+func (f *File) GenerateEditScript(target *File) (EditScript, error) {
+	if len(f.Errors) > 0 {
+		return nil, fmt.Errorf("current file has %d parse errors", len(f.Errors))
+	}
+	if len(target.Errors) > 0 {
+		return nil, fmt.Errorf("target file has %d parse errors", len(target.Errors))
+	}
+
+	// pair[i] is the index in f.Blocks of the block paired with
+	// target.Blocks[i], or -1.
+	pair := pairBlocks(f.Blocks, target.Blocks)
+
+	paired := map[int]bool{}
+	var order []int // indexes in f.Blocks of paired blocks, in target order
+	for _, cur := range pair {
+		if cur >= 0 {
+			paired[cur] = true
+			order = append(order, cur)
+		}
+	}
+	inOrder := longestSortedSubsequence(order)
+	keep := map[int]bool{}
+	for i, cur := range order {
+		if inOrder[i] {
+			keep[cur] = true
+		}
+	}
+
+	var ret EditScript
+
+	// work tracks the blocks during editing. Each element is the
+	// block's index in f.Blocks, or -1-i for target.Blocks[i].
+	var work []int
+	for i := range f.Blocks {
+		work = append(work, i)
+	}
+	for i := len(f.Blocks) - 1; i >= 0; i-- {
+		if !paired[i] {
+			ret = append(ret, RemoveBlock{Index: i})
+			work = slices.Delete(work, i, i+1)
+		}
+	}
+
+	for i, cur := range pair {
+		// Every block not kept in place is placed directly after its
+		// predecessor in target. Blocks are placed in target order,
+		// so that the predecessor is always already in place.
+		var id int
+		switch {
+		case cur < 0:
+			id = -1 - i
+			pos := placeAfter(work, pair, i)
+			ret = append(ret, InsertBlock{Index: pos, Block: target.Blocks[i]})
+			work = slices.Insert(work, pos, id)
+			continue
+		case keep[cur]:
+			id = cur
+		default:
+			id = cur
+			from := slices.Index(work, id)
+			to := placeAfter(work, pair, i)
+			if from < to {
+				to--
+			}
+			if from != to {
+				ret = append(ret, MoveBlock{From: from, To: to})
+				work = slices.Delete(work, from, from+1)
+				work = slices.Insert(work, to, id)
+			}
+		}
+
+		if f.Blocks[cur].source().Raw != target.Blocks[i].source().Raw {
+			ret = append(ret, ModifyBlock{Index: slices.Index(work, id), Block: target.Blocks[i]})
+		}
+	}
+
+	return ret, nil
+}
+
+// pairBlocks returns, for each block of target, the index of the
+// paired block in cur, or -1 if it has none. Blocks with identical
+// source text are paired first, then suffix blocks with the same
+// entity name.
+func pairBlocks(cur, target []Block) []int {
+	ret := make([]int, len(target))
+	used := make([]bool, len(cur))
+	for i := range ret {
+		ret[i] = -1
+	}
+
+	pairWith := func(match func(a, b Block) bool) {
+		for i, t := range target {
+			if ret[i] >= 0 {
+				continue
+			}
+			for j, c := range cur {
+				if !used[j] && match(c, t) {
+					ret[i] = j
+					used[j] = true
+					break
+				}
+			}
+		}
+	}
+	pairWith(func(a, b Block) bool {
+		return reflect.TypeOf(a) == reflect.TypeOf(b) && a.source().Raw == b.source().Raw
+	})
+	pairWith(func(a, b Block) bool {
+		sa, ok1 := a.(Suffixes)
+		sb, ok2 := b.(Suffixes)
+		return ok1 && ok2 && sa.Entity != "" && sa.Entity == sb.Entity
+	})
+
+	return ret
+}
+
+// placeAfter returns the index in work just after the block that
+// precedes target.Blocks[i], where pair is as returned by pairBlocks.
+func placeAfter(work []int, pair []int, i int) int {
+	if i == 0 {
+		return 0
+	}
+	prev := pair[i-1]
+	if prev < 0 {
+		prev = -1 - (i - 1)
+	}
+	return slices.Index(work, prev) + 1
+}
+
+// Apply applies the edit script s to f, and returns the result as a
+// new File. f is not modified.
+//
+// The blocks of the result are renumbered with RenumberLines. The
+// result has no Errors or Warnings, parse the output of Marshal to
+// validate it.
+func (s EditScript) Apply(f *File) (*File, error) {
+	blocks := slices.Clone(f.Blocks)
+	for i, op := range s {
+		var err error
+		blocks, err = op.apply(blocks)
+		if err != nil {
+			return nil, fmt.Errorf("edit %d (%T): %w", i, op, err)
+		}
+	}
+
+	ret := &File{Blocks: blocks}
+	ret.RenumberLines()
+	return ret, nil
+}
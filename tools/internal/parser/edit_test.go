@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+func TestEditScript(t *testing.T) {
+	// Split testFile into its blocks, so that test cases can
+	// rearrange them. The end of the ICANN section and the start of
+	// the private section are a single chunk, since they are not
+	// separated by an empty line.
+	var (
+		chunks     = strings.Split(testFile, "\n\n")
+		icann      = strings.Join(chunks[:3], "\n\n")
+		awsThing   = chunks[3]
+		awsOther   = chunks[4]
+		duck       = chunks[5]
+		noURL      = chunks[6]
+		privateEnd = chunks[7]
+	)
+	join := func(parts ...string) string {
+		return strings.Join(parts, "\n\n")
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		// want returns the expected edit script, given the parsed
+		// target file.
+		want func(target *File) EditScript
+	}{
+		{
+			name:   "identical",
+			target: testFile,
+			want:   func(*File) EditScript { return nil },
+		},
+		{
+			name:   "remove",
+			target: join(icann, awsThing, duck, noURL, privateEnd),
+			want: func(*File) EditScript {
+				return EditScript{RemoveBlock{Index: 5}}
+			},
+		},
+		{
+			name: "insert",
+			target: join(icann, awsThing, awsOther, dedent(`
+			  // New Corp : https://new.example
+			  // Submitted by Newcomer <new@example.com>
+			  new.example
+			`), duck, noURL, privateEnd),
+			want: func(target *File) EditScript {
+				return EditScript{InsertBlock{Index: 6, Block: target.Blocks[6]}}
+			},
+		},
+		{
+			name:   "move",
+			target: join(icann, duck, awsThing, awsOther, noURL, privateEnd),
+			want: func(*File) EditScript {
+				return EditScript{MoveBlock{From: 6, To: 4}}
+			},
+		},
+		{
+			name: "modify",
+			target: join(icann, awsThing, awsOther, dedent(`
+			  // DuckCorp Inc : https://duck.example.org/
+			  // Submitted by Not A Duck <duck@example.com>
+			  duck.example
+			  quack.duck.example
+			`), noURL, privateEnd),
+			want: func(target *File) EditScript {
+				return EditScript{ModifyBlock{Index: 6, Block: target.Blocks[6]}}
+			},
+		},
+		{
+			name: "move_and_modify",
+			target: join(icann, dedent(`
+			  // No URL Corp
+			  // Submitted by Somebody <somebody@example.com>
+			  nourl.example
+			`), awsThing, awsOther, duck, privateEnd),
+			want: func(target *File) EditScript {
+				return EditScript{
+					MoveBlock{From: 7, To: 4},
+					ModifyBlock{Index: 4, Block: target.Blocks[4]},
+				}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			current := Parse(testFile)
+			target := Parse(test.target)
+			if len(target.Errors) > 0 {
+				t.Fatalf("unexpected parse errors: %v", target.Errors)
+			}
+
+			script, err := current.GenerateEditScript(target)
+			if err != nil {
+				t.Fatalf("GenerateEditScript() failed: %v", err)
+			}
+			if diff := diff.Diff(test.want(target), script); diff != "" {
+				t.Errorf("GenerateEditScript() wrong script (-want +got):\n%s", diff)
+			}
+
+			got, err := script.Apply(current)
+			if err != nil {
+				t.Fatalf("Apply() failed: %v", err)
+			}
+			if diff := diff.Diff(target.Blocks, got.Blocks); diff != "" {
+				t.Errorf("Apply() wrong result (-want +got):\n%s", diff)
+			}
+			if diff := diff.Diff(testFile+"\n", string(current.Marshal())); diff != "" {
+				t.Errorf("Apply() modified its input (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEditScriptErrors(t *testing.T) {
+	good := Parse(testFile)
+	bad := Parse("// ===BEGIN ICANN DOMAINS===\n\nexample")
+	if _, err := good.GenerateEditScript(bad); err == nil {
+		t.Error("GenerateEditScript() with broken target did not return an error")
+	}
+	if _, err := bad.GenerateEditScript(good); err == nil {
+		t.Error("GenerateEditScript() with broken current file did not return an error")
+	}
+
+	script := EditScript{RemoveBlock{Index: 100}}
+	if _, err := script.Apply(good); err == nil {
+		t.Error("Apply() with out of range edit did not return an error")
+	}
+}
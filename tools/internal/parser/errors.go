@@ -2,8 +2,50 @@ package parser
 
 import (
 	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
 )
 
+// Fixer is implemented by errors that have an obvious mechanical
+// fix, so that tools such as editors can offer to apply it.
+//
+// Fixit returns the replacement text for the single source line that
+// the error is about, which is the Source returned by the error's
+// Location method, or ok=false if the error has no fix. All Fixers
+// also implement Locator.
+type Fixer interface {
+	Fixit() (replacement string, ok bool)
+}
+
+// Locator is implemented by errors that are about a specific place in
+// the source text.
+//
+// Location returns the source that the error is about. For errors
+// that involve several lines or blocks, such as a duplicate entry,
+// that is the one that should change to fix the error, for example
+// the second copy of the duplicate.
+type Locator interface {
+	Location() Source
+}
+
+// Warning is implemented by errors that report a problem that does
+// not make a PSL file invalid, such as a style issue. Parse records
+// these in File.Warnings rather than File.Errors, and so do the
+// ExtraValidators of ParseOptions.
+type Warning interface {
+	error
+	IsWarning() bool
+}
+
+// ByteOrderMarkError reports that the file starts with a Unicode
+// byte order mark. PSL files are UTF-8 and must not have one.
+type ByteOrderMarkError struct{}
+
+func (e ByteOrderMarkError) Error() string {
+	return "file starts with a byte order mark, which must be removed"
+}
+
 // UnclosedSectionError reports that a file section was not closed
 // properly before EOF.
 type UnclosedSectionError struct {
@@ -14,16 +56,26 @@ func (e UnclosedSectionError) Error() string {
 	return fmt.Sprintf("section %q started at %s, but is never closed", e.Start.Name, e.Start.LocationString())
 }
 
+func (e UnclosedSectionError) Location() Source {
+	return e.Start.Source
+}
+
 // NestedSectionError reports that a file section is being started
 // while already within a section, which the PSL format does not
 // allow.
 type NestedSectionError struct {
 	Outer StartSection
 	Inner StartSection
+	// Suggestion is the end marker that closes Outer.
+	Suggestion string
 }
 
 func (e NestedSectionError) Error() string {
-	return fmt.Sprintf("new section %q started at %s while still in section %q (started at %s)", e.Inner.Name, e.Inner.LocationString(), e.Outer.Name, e.Outer.LocationString())
+	return fmt.Sprintf("new section %q started at %s while still in section %q (started at %s), add %q before it to close the earlier section", e.Inner.Name, e.Inner.LocationString(), e.Outer.Name, e.Outer.LocationString(), e.Suggestion)
+}
+
+func (e NestedSectionError) Location() Source {
+	return e.Inner.Source
 }
 
 // UnstartedSectionError reports that a file section end marker was
@@ -36,6 +88,10 @@ func (e UnstartedSectionError) Error() string {
 	return fmt.Sprintf("section %q closed at %s but was not started", e.End.Name, e.End.LocationString())
 }
 
+func (e UnstartedSectionError) Location() Source {
+	return e.End.Source
+}
+
 // MismatchedSectionError reports that a file section was started
 // under one name but ended under another.
 type MismatchedSectionError struct {
@@ -47,6 +103,88 @@ func (e MismatchedSectionError) Error() string {
 	return fmt.Sprintf("section %q closed at %s while in section %q (started at %s)", e.End.Name, e.End.LocationString(), e.Start.Name, e.Start.LocationString())
 }
 
+func (e MismatchedSectionError) Location() Source {
+	return e.End.Source
+}
+
+// SectionEndCasingError reports that a file section was ended under
+// the same name it was started with, but with different
+// capitalization, e.g. "===BEGIN ICANN DOMAINS===" closed by
+// "===END icann domains===". The section is treated as correctly
+// closed, so this is reported as a warning, and the parsed EndSection
+// uses the name from the start marker. End is the end marker as
+// written.
+type SectionEndCasingError struct {
+	Start StartSection
+	End   EndSection
+	// Suggestion is the end marker with the name capitalized as in
+	// Start.
+	Suggestion string
+}
+
+func (e SectionEndCasingError) Error() string {
+	return fmt.Sprintf("section %q closed at %s as %q, capitalization must match the start at %s, did you mean %q?", e.Start.Name, e.End.LocationString(), e.End.Name, e.Start.LocationString(), e.Suggestion)
+}
+
+func (e SectionEndCasingError) Fixit() (string, bool) {
+	return e.Suggestion, true
+}
+
+func (e SectionEndCasingError) Location() Source {
+	return e.End.Source
+}
+
+func (e SectionEndCasingError) IsWarning() bool {
+	return true
+}
+
+// MalformedSectionDelimiterError reports that a section marker has
+// more than three "=" characters on either side of the marker text,
+// e.g. "// ====BEGIN ICANN DOMAINS====".
+type MalformedSectionDelimiterError struct {
+	Line Source
+	// Suggestion is the marker with the correct delimiters.
+	Suggestion string
+}
+
+func (e MalformedSectionDelimiterError) Error() string {
+	return fmt.Sprintf("section marker %q at %s must use exactly three \"=\" on each side, did you mean %q?", e.Line.Raw, e.Line.LocationString(), e.Suggestion)
+}
+
+func (e MalformedSectionDelimiterError) Fixit() (string, bool) {
+	return e.Suggestion, true
+}
+
+func (e MalformedSectionDelimiterError) Location() Source {
+	return e.Line
+}
+
+// SectionNameWhitespaceWarning reports that the name in a section
+// marker has extra spaces around it or between its words, e.g.
+// "// ===BEGIN  ICANN DOMAINS===". The parser uses the name without
+// the extra spaces.
+type SectionNameWhitespaceWarning struct {
+	Line Source
+	// Suggestion is the marker without the extra spaces.
+	Suggestion string
+}
+
+func (e SectionNameWhitespaceWarning) Error() string {
+	return fmt.Sprintf("section marker %q at %s has extra spaces in the section name, did you mean %q?", e.Line.Raw, e.Line.LocationString(), e.Suggestion)
+}
+
+func (e SectionNameWhitespaceWarning) Fixit() (string, bool) {
+	return e.Suggestion, true
+}
+
+func (e SectionNameWhitespaceWarning) Location() Source {
+	return e.Line
+}
+
+func (e SectionNameWhitespaceWarning) IsWarning() bool {
+	return true
+}
+
 // UnknownSectionMarker reports that a line looks like a file section
 // marker (e.g. "===BEGIN ICANN DOMAINS==="), but is not one of the
 // recognized kinds of marker.
@@ -58,6 +196,10 @@ func (e UnknownSectionMarker) Error() string {
 	return fmt.Sprintf("unknown kind of section marker %q at %s", trimComment(e.Line.Raw), e.Line.LocationString())
 }
 
+func (e UnknownSectionMarker) Location() Source {
+	return e.Line
+}
+
 // MixedCommentsAndSectionMarkers reports that a block contains both
 // ordinary top-level comments and section marker lines
 // (e.g. "===BEGIN ICANN DOMAINS==="). Section markers should be alone
@@ -70,6 +212,10 @@ func (e MixedCommentsAndSectionMarkers) Error() string {
 	return fmt.Sprintf("invalid comment block with mixed freeform comments and section markers at %s", e.Lines.LocationString())
 }
 
+func (e MixedCommentsAndSectionMarkers) Location() Source {
+	return e.Lines
+}
+
 // UnterminatedSectionMarker reports that a section marker is missing
 // the required trailing "===", e.g. "===BEGIN ICANN DOMAINS".
 type UnterminatedSectionMarker struct {
@@ -80,6 +226,10 @@ func (e UnterminatedSectionMarker) Error() string {
 	return fmt.Sprintf(`section marker %q at %s is missing trailing "==="`, trimComment(e.Line.Raw), e.Line.LocationString())
 }
 
+func (e UnterminatedSectionMarker) Location() Source {
+	return e.Line
+}
+
 // MissingEntityName reports that a block of suffixes does not have a
 // parseable owner name in its header comment.
 type MissingEntityName struct {
@@ -90,6 +240,10 @@ func (e MissingEntityName) Error() string {
 	return fmt.Sprintf("could not find entity name for %s at %s", e.Suffixes.shortName(), e.Suffixes.LocationString())
 }
 
+func (e MissingEntityName) Location() Source {
+	return e.Suffixes.Source
+}
+
 // MissingEntityEmail reports that a block of suffixes does not have a
 // parseable contact email address in its header comment.
 type MissingEntityEmail struct {
@@ -99,3 +253,1044 @@ type MissingEntityEmail struct {
 func (e MissingEntityEmail) Error() string {
 	return fmt.Sprintf("could not find a contact email for %s at %s", e.Suffixes.shortName(), e.Suffixes.LocationString())
 }
+
+func (e MissingEntityEmail) Location() Source {
+	return e.Suffixes.Source
+}
+
+// MissingSectionError reports that a file section required by the PSL
+// format is not present.
+type MissingSectionError struct {
+	Name string // e.g. "ICANN DOMAINS", "PRIVATE DOMAINS"
+}
+
+func (e MissingSectionError) Error() string {
+	return fmt.Sprintf("required section %q is missing", e.Name)
+}
+
+// SectionOutOfOrderError reports that the private domains section
+// appears before the ICANN domains section.
+type SectionOutOfOrderError struct {
+	ICANN   StartSection
+	Private StartSection
+}
+
+func (e SectionOutOfOrderError) Error() string {
+	return fmt.Sprintf("section %q at %s must come after section %q at %s", e.Private.Name, e.Private.LocationString(), e.ICANN.Name, e.ICANN.LocationString())
+}
+
+func (e SectionOutOfOrderError) Location() Source {
+	return e.Private.Source
+}
+
+// SuffixBlocksInWrongPlace reports that the suffix blocks of the
+// private domains section are not sorted by entity name. Blocks are
+// the blocks that must move for the section to be sorted, and
+// EditScript says where to move each of them. The steps of EditScript
+// must be applied in order, since a step may place a block relative
+// to one moved by an earlier step.
+type SuffixBlocksInWrongPlace struct {
+	Blocks     []Suffixes
+	EditScript []MoveSuffixBlock
+}
+
+// MoveSuffixBlock is one step of the edit script in
+// SuffixBlocksInWrongPlace: move the block for entity Name to just
+// after the block for entity InsertAfter, or to the start of the
+// section if InsertAfter is empty.
+//
+// MoveSuffixBlock is suitable for encoding as JSON, for consumption by
+// other tools.
+type MoveSuffixBlock struct {
+	Name        string `json:"name"`
+	InsertAfter string `json:"insert_after,omitempty"`
+}
+
+func (e SuffixBlocksInWrongPlace) Error() string {
+	var names []string
+	for _, block := range e.Blocks {
+		names = append(names, block.shortName())
+	}
+	return fmt.Sprintf("%d suffix blocks are not sorted by entity name: %s", len(e.Blocks), strings.Join(names, ", "))
+}
+
+func (e SuffixBlocksInWrongPlace) Location() Source {
+	return e.Blocks[0].Source
+}
+
+// UnsortedExceptionsWarning reports that the exception rules for a
+// wildcard are not listed in sorted order directly after the
+// wildcard in its suffix block. Exceptions are the exception rules in
+// the order they appear, and EditScript says how to move them into
+// canonical order.
+type UnsortedExceptionsWarning struct {
+	Wildcard   Source
+	Exceptions []Source
+	EditScript []MoveSuffixEntry
+}
+
+// MoveSuffixEntry is one step of the edit script in
+// UnsortedExceptionsWarning: move the suffix entry Entry to the line
+// just after the entry InsertAfter, in the same block.
+//
+// MoveSuffixEntry is suitable for encoding as JSON, for consumption by
+// other tools.
+type MoveSuffixEntry struct {
+	Entry       string `json:"entry"`
+	InsertAfter string `json:"insert_after"`
+}
+
+func (e UnsortedExceptionsWarning) Error() string {
+	return fmt.Sprintf("exceptions to wildcard %q at %s should be sorted and listed directly after the wildcard", e.Wildcard.Raw, e.Wildcard.LocationString())
+}
+
+func (e UnsortedExceptionsWarning) Location() Source {
+	return e.Wildcard
+}
+
+func (e UnsortedExceptionsWarning) IsWarning() bool {
+	return true
+}
+
+// TooManyErrorsError reports that parsing was stopped early because
+// the number of errors exceeded ParseOptions.MaxErrors.
+type TooManyErrorsError struct {
+	Max int
+}
+
+func (e TooManyErrorsError) Error() string {
+	return fmt.Sprintf("too many errors (more than %d), parsing stopped", e.Max)
+}
+
+// InvalidDomainError reports that a domain passed to a lookup
+// function is not well formed, for example because it contains empty
+// labels.
+type InvalidDomainError struct {
+	Domain string
+}
+
+func (e InvalidDomainError) Error() string {
+	return fmt.Sprintf("invalid domain %q", e.Domain)
+}
+
+// NoRegistrableDomainError reports that a domain has no registrable
+// domain, because the domain is itself a public suffix.
+type NoRegistrableDomainError struct {
+	Domain string
+}
+
+func (e NoRegistrableDomainError) Error() string {
+	return fmt.Sprintf("%q is a public suffix and has no registrable domain", e.Domain)
+}
+
+// NumericSuffixError reports that a suffix entry is an IP address, or
+// is made up entirely of numeric labels. Neither is a valid public
+// suffix.
+type NumericSuffixError struct {
+	Line Source
+}
+
+func (e NumericSuffixError) Error() string {
+	return fmt.Sprintf("suffix %q at %s is numeric or an IP address, not a domain name", e.Line.Raw, e.Line.LocationString())
+}
+
+func (e NumericSuffixError) Location() Source {
+	return e.Line
+}
+
+// MultipleSuffixesOnLineError reports that a suffix entry contains
+// whitespace, which usually means that several suffixes were pasted
+// onto one line. Each suffix must be on its own line.
+type MultipleSuffixesOnLineError struct {
+	Line Source
+	// Suffixes are the whitespace-separated parts of the line, which
+	// should each be on a separate line.
+	Suffixes []string
+}
+
+func (e MultipleSuffixesOnLineError) Error() string {
+	return fmt.Sprintf("suffix line %q at %s contains whitespace, put each of %q on its own line", e.Line.Raw, e.Line.LocationString(), e.Suffixes)
+}
+
+func (e MultipleSuffixesOnLineError) Location() Source {
+	return e.Line
+}
+
+// LabelConversionError reports that a label of a suffix entry could
+// not be converted to its canonical punycode form.
+type LabelConversionError struct {
+	Line  Source
+	Label string
+	Err   error
+}
+
+func (e LabelConversionError) Error() string {
+	return fmt.Sprintf("cannot convert label %q of suffix %q at %s to punycode: %v", e.Label, e.Line.Raw, e.Line.LocationString(), e.Err)
+}
+
+func (e LabelConversionError) Location() Source {
+	return e.Line
+}
+
+// SuspiciouslyShortEntityWarning reports that a block of suffixes has
+// an entity name so short that it is likely a placeholder.
+type SuspiciouslyShortEntityWarning struct {
+	Suffixes Suffixes
+}
+
+func (e SuspiciouslyShortEntityWarning) Error() string {
+	return fmt.Sprintf("entity name %q at %s is suspiciously short", e.Suffixes.Entity, e.Suffixes.LocationString())
+}
+
+func (e SuspiciouslyShortEntityWarning) Location() Source {
+	return e.Suffixes.Source
+}
+
+func (e SuspiciouslyShortEntityWarning) IsWarning() bool {
+	return true
+}
+
+// NonAlphanumericEntityWarning reports that a block of suffixes has an
+// entity name that contains no letters, e.g. "123" or "...".
+type NonAlphanumericEntityWarning struct {
+	Suffixes Suffixes
+}
+
+func (e NonAlphanumericEntityWarning) Error() string {
+	return fmt.Sprintf("entity name %q at %s contains no letters", e.Suffixes.Entity, e.Suffixes.LocationString())
+}
+
+func (e NonAlphanumericEntityWarning) Location() Source {
+	return e.Suffixes.Source
+}
+
+func (e NonAlphanumericEntityWarning) IsWarning() bool {
+	return true
+}
+
+// UppercaseSuffixWarning reports that a suffix entry contains
+// uppercase characters. PSL entries are canonically lowercase.
+type UppercaseSuffixWarning struct {
+	Line Source
+	// Lowercase is the canonical lowercase form of the entry.
+	Lowercase string
+}
+
+func (e UppercaseSuffixWarning) Error() string {
+	return fmt.Sprintf("suffix %q at %s should be lowercase: %q", e.Line.Raw, e.Line.LocationString(), e.Lowercase)
+}
+
+func (e UppercaseSuffixWarning) Fixit() (string, bool) {
+	return e.Lowercase, true
+}
+
+func (e UppercaseSuffixWarning) Location() Source {
+	return e.Line
+}
+
+func (e UppercaseSuffixWarning) IsWarning() bool {
+	return true
+}
+
+// NonContiguousWildcardGroupWarning reports that an ordinary suffix
+// entry separates a wildcard from its exceptions. By convention,
+// exceptions are listed directly after their wildcard, or after a
+// group of related wildcards.
+type NonContiguousWildcardGroupWarning struct {
+	Wildcard Source
+	// Separator is the first ordinary entry between Wildcard and its
+	// exceptions.
+	Separator Source
+}
+
+func (e NonContiguousWildcardGroupWarning) Error() string {
+	return fmt.Sprintf("suffix %q at %s separates wildcard %q at %s from its exceptions", e.Separator.Raw, e.Separator.LocationString(), e.Wildcard.Raw, e.Wildcard.LocationString())
+}
+
+func (e NonContiguousWildcardGroupWarning) Location() Source {
+	return e.Separator
+}
+
+func (e NonContiguousWildcardGroupWarning) IsWarning() bool {
+	return true
+}
+
+// InvalidExceptionError reports that an exception rule is not a
+// direct subdomain of any wildcard, but is related to a wildcard in a
+// way that suggests a mistake. For example, "!foo.example" names the
+// domain of the wildcard "*.foo.example" rather than a subdomain.
+type InvalidExceptionError struct {
+	Exception Source
+	Wildcard  Source
+}
+
+func (e InvalidExceptionError) Error() string {
+	_, domain := splitRulePrefix(e.Wildcard.Raw)
+	return fmt.Sprintf("exception %q at %s does not carve out a domain from wildcard %q at %s, exception must be a direct subdomain of the wildcard, e.g. \"!X.%s\"", e.Exception.Raw, e.Exception.LocationString(), e.Wildcard.Raw, e.Wildcard.LocationString(), domain)
+}
+
+func (e InvalidExceptionError) Location() Source {
+	return e.Exception
+}
+
+// URLDomainMismatchWarning reports that none of the suffixes in a
+// block share a registrable domain with the block's URL.
+type URLDomainMismatchWarning struct {
+	Suffixes Suffixes
+	// URLDomain is the registrable domain of the block's URL.
+	URLDomain string
+}
+
+func (e URLDomainMismatchWarning) Error() string {
+	return fmt.Sprintf("none of the suffixes for %s at %s are under the URL's domain %q", e.Suffixes.shortName(), e.Suffixes.LocationString(), e.URLDomain)
+}
+
+func (e URLDomainMismatchWarning) Location() Source {
+	return e.Suffixes.Source
+}
+
+func (e URLDomainMismatchWarning) IsWarning() bool {
+	return true
+}
+
+// EmailDomainMismatchWarning reports that the contact email of a
+// block is not on the same registrable domain as any of the block's
+// suffixes, or its URL.
+type EmailDomainMismatchWarning struct {
+	Suffixes Suffixes
+	// Line is the header line that contains the email address.
+	Line Source
+	// EmailDomain is the registrable domain of the email address.
+	EmailDomain string
+}
+
+func (e EmailDomainMismatchWarning) Error() string {
+	return fmt.Sprintf("contact email domain %q at %s is unrelated to the suffixes and URL of %s", e.EmailDomain, e.Line.LocationString(), e.Suffixes.shortName())
+}
+
+func (e EmailDomainMismatchWarning) Location() Source {
+	return e.Line
+}
+
+func (e EmailDomainMismatchWarning) IsWarning() bool {
+	return true
+}
+
+// CombinedURLEmailWarning reports that a suffix block header line
+// has the submitter's email address on the same line as the URL, as
+// in "// Example : https://example.com contact@example.com". The URL
+// and email are both extracted, but belong on separate lines.
+type CombinedURLEmailWarning struct {
+	Line Source
+}
+
+func (e CombinedURLEmailWarning) Error() string {
+	return fmt.Sprintf("URL and email address on the same line at %s, email should be on its own \"Submitted by\" line", e.Line.LocationString())
+}
+
+func (e CombinedURLEmailWarning) Location() Source {
+	return e.Line
+}
+
+func (e CombinedURLEmailWarning) IsWarning() bool {
+	return true
+}
+
+// MalformedSectionMarker reports that a comment line looks like an
+// attempted section marker, but is not formatted correctly.
+type MalformedSectionMarker struct {
+	Line Source
+	// Suggestion is the correctly formatted section marker.
+	Suggestion string
+}
+
+func (e MalformedSectionMarker) Error() string {
+	return fmt.Sprintf("malformed section marker %q at %s, did you mean %q?", e.Line.Raw, e.Line.LocationString(), e.Suggestion)
+}
+
+func (e MalformedSectionMarker) Fixit() (string, bool) {
+	return e.Suggestion, true
+}
+
+func (e MalformedSectionMarker) Location() Source {
+	return e.Line
+}
+
+// URLNotReachableError reports that a suffix block's URL could not be
+// fetched, or responded with a non-2xx HTTP status.
+type URLNotReachableError struct {
+	Suffixes Suffixes
+	URL      *url.URL
+	// StatusCode is the HTTP status of the response, or 0 if no
+	// response was received.
+	StatusCode int
+	// Err is the error that prevented a response from being
+	// received, if any.
+	Err error
+}
+
+func (e URLNotReachableError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("URL %q for %s at %s is not reachable: %v", e.URL, e.Suffixes.shortName(), e.Suffixes.LocationString(), e.Err)
+	}
+	return fmt.Sprintf("URL %q for %s at %s returned HTTP status %d", e.URL, e.Suffixes.shortName(), e.Suffixes.LocationString(), e.StatusCode)
+}
+
+func (e URLNotReachableError) Location() Source {
+	return e.Suffixes.Source
+}
+
+// LeadingDotError reports that a suffix entry starts with a dot,
+// e.g. ".example.com".
+type LeadingDotError struct {
+	Line Source
+	// Suggestion is the entry with leading and trailing dots
+	// removed.
+	Suggestion string
+}
+
+func (e LeadingDotError) Error() string {
+	return fmt.Sprintf("suffix %q at %s has a leading dot, did you mean %q?", e.Line.Raw, e.Line.LocationString(), e.Suggestion)
+}
+
+func (e LeadingDotError) Fixit() (string, bool) {
+	return e.Suggestion, true
+}
+
+func (e LeadingDotError) Location() Source {
+	return e.Line
+}
+
+// TrailingDotError reports that a suffix entry ends with a dot,
+// e.g. "example.com.". PSL entries are not written as fully qualified
+// domain names.
+type TrailingDotError struct {
+	Line Source
+	// Suggestion is the entry with leading and trailing dots
+	// removed.
+	Suggestion string
+}
+
+func (e TrailingDotError) Error() string {
+	return fmt.Sprintf("suffix %q at %s has a trailing dot, did you mean %q?", e.Line.Raw, e.Line.LocationString(), e.Suggestion)
+}
+
+func (e TrailingDotError) Fixit() (string, bool) {
+	return e.Suggestion, true
+}
+
+func (e TrailingDotError) Location() Source {
+	return e.Line
+}
+
+// ContentBetweenSectionsError reports that a block appears between
+// the end of one file section and the start of the next. Sections
+// must follow each other directly.
+type ContentBetweenSectionsError struct {
+	Block  Block
+	After  EndSection
+	Before StartSection
+}
+
+func (e ContentBetweenSectionsError) Error() string {
+	return fmt.Sprintf("unexpected content at %s between the end of section %q and the start of section %q", e.Block.source().LocationString(), e.After.Name, e.Before.Name)
+}
+
+func (e ContentBetweenSectionsError) Location() Source {
+	return e.Block.source()
+}
+
+// SuffixOutsideSectionError reports that a block of suffixes appears
+// before the first file section or after the last one. All suffixes
+// must be in either the ICANN or the private domains section.
+type SuffixOutsideSectionError struct {
+	Suffixes Suffixes
+}
+
+func (e SuffixOutsideSectionError) Error() string {
+	return fmt.Sprintf("suffixes for %s at %s are outside of any file section, suffix blocks must be inside a section", e.Suffixes.shortName(), e.Suffixes.LocationString())
+}
+
+func (e SuffixOutsideSectionError) Location() Source {
+	return e.Suffixes.Source
+}
+
+// AllCapsEntityNameWarning reports that a block of suffixes has an
+// entity name written entirely in uppercase.
+type AllCapsEntityNameWarning struct {
+	Suffixes Suffixes
+}
+
+func (e AllCapsEntityNameWarning) Error() string {
+	return fmt.Sprintf("entity name %q at %s is all uppercase", e.Suffixes.Entity, e.Suffixes.LocationString())
+}
+
+func (e AllCapsEntityNameWarning) Location() Source {
+	return e.Suffixes.Source
+}
+
+func (e AllCapsEntityNameWarning) IsWarning() bool {
+	return true
+}
+
+// AllLowerCaseEntityNameWarning reports that a block of suffixes has
+// an entity name written entirely in lowercase.
+type AllLowerCaseEntityNameWarning struct {
+	Suffixes Suffixes
+}
+
+func (e AllLowerCaseEntityNameWarning) Error() string {
+	return fmt.Sprintf("entity name %q at %s is all lowercase", e.Suffixes.Entity, e.Suffixes.LocationString())
+}
+
+func (e AllLowerCaseEntityNameWarning) Location() Source {
+	return e.Suffixes.Source
+}
+
+func (e AllLowerCaseEntityNameWarning) IsWarning() bool {
+	return true
+}
+
+// SubdomainConflictWarning reports that a suffix entry is a subdomain
+// of another entry, so that domains under Child match two rules.
+type SubdomainConflictWarning struct {
+	Parent Source
+	Child  Source
+}
+
+func (e SubdomainConflictWarning) Error() string {
+	return fmt.Sprintf("suffix %q at %s is a subdomain of suffix %q at %s", e.Child.Raw, e.Child.LocationString(), e.Parent.Raw, e.Parent.LocationString())
+}
+
+func (e SubdomainConflictWarning) Location() Source {
+	return e.Child
+}
+
+func (e SubdomainConflictWarning) IsWarning() bool {
+	return true
+}
+
+// MissingBlankAfterSectionMarkerWarning reports that a block
+// immediately follows the start of a file section, without an empty
+// line in between.
+type MissingBlankAfterSectionMarkerWarning struct {
+	Marker StartSection
+	Block  Block
+}
+
+func (e MissingBlankAfterSectionMarkerWarning) Error() string {
+	return fmt.Sprintf("missing empty line between start of section %q at %s and the block at %s", e.Marker.Name, e.Marker.LocationString(), e.Block.source().LocationString())
+}
+
+func (e MissingBlankAfterSectionMarkerWarning) Location() Source {
+	return e.Block.source()
+}
+
+func (e MissingBlankAfterSectionMarkerWarning) IsWarning() bool {
+	return true
+}
+
+// MissingBlankBeforeSectionMarkerWarning reports that a block
+// immediately precedes the end of a file section, without an empty
+// line in between.
+type MissingBlankBeforeSectionMarkerWarning struct {
+	Marker EndSection
+	Block  Block
+}
+
+func (e MissingBlankBeforeSectionMarkerWarning) Error() string {
+	return fmt.Sprintf("missing empty line between the block at %s and end of section %q at %s", e.Block.source().LocationString(), e.Marker.Name, e.Marker.LocationString())
+}
+
+func (e MissingBlankBeforeSectionMarkerWarning) Location() Source {
+	return e.Marker.Source
+}
+
+func (e MissingBlankBeforeSectionMarkerWarning) IsWarning() bool {
+	return true
+}
+
+// TooManySuffixesWarning reports that a block of suffixes has more
+// entries than a configured threshold.
+type TooManySuffixesWarning struct {
+	Suffixes Suffixes
+	Count    int
+}
+
+func (e TooManySuffixesWarning) Error() string {
+	return fmt.Sprintf("suffix block for %q at %s has %d suffixes, consider splitting it", e.Suffixes.Entity, e.Suffixes.LocationString(), e.Count)
+}
+
+func (e TooManySuffixesWarning) Location() Source {
+	return e.Suffixes.Source
+}
+
+func (e TooManySuffixesWarning) IsWarning() bool {
+	return true
+}
+
+// TooFewSuffixesWarning reports that a block of suffixes has fewer
+// non-exception entries than a configured minimum.
+type TooFewSuffixesWarning struct {
+	Suffixes Suffixes
+	Count    int
+}
+
+func (e TooFewSuffixesWarning) Error() string {
+	return fmt.Sprintf("suffix block for %q at %s has only %d non-exception suffixes, it may be incomplete", e.Suffixes.Entity, e.Suffixes.LocationString(), e.Count)
+}
+
+func (e TooFewSuffixesWarning) Location() Source {
+	return e.Suffixes.Source
+}
+
+func (e TooFewSuffixesWarning) IsWarning() bool {
+	return true
+}
+
+// DeepPathURLWarning reports that a block of suffixes has a URL with
+// a deep path, which is more likely to break over time than a
+// top-level page.
+type DeepPathURLWarning struct {
+	Suffixes Suffixes
+	URL      *url.URL
+}
+
+func (e DeepPathURLWarning) Error() string {
+	return fmt.Sprintf("URL %q for %s has a deep path, prefer a top-level page", e.URL, e.Suffixes.shortName())
+}
+
+func (e DeepPathURLWarning) Location() Source {
+	return e.Suffixes.Source
+}
+
+func (e DeepPathURLWarning) IsWarning() bool {
+	return true
+}
+
+// SharedURLDifferentEntityWarning reports that several blocks of
+// suffixes with different entity names have the same URL. This can
+// indicate an entity whose suffixes were split across several blocks
+// under slightly different names.
+type SharedURLDifferentEntityWarning struct {
+	URL    string
+	Blocks []Suffixes
+}
+
+func (e SharedURLDifferentEntityWarning) Error() string {
+	var locs []string
+	for _, block := range e.Blocks {
+		locs = append(locs, fmt.Sprintf("%q at %s", block.Entity, block.LocationString()))
+	}
+	return fmt.Sprintf("URL %q is shared by suffix blocks with different entity names: %s", e.URL, strings.Join(locs, ", "))
+}
+
+func (e SharedURLDifferentEntityWarning) Location() Source {
+	return e.Blocks[1].Source
+}
+
+func (e SharedURLDifferentEntityWarning) IsWarning() bool {
+	return true
+}
+
+// DuplicateEntityWarning reports that two blocks of suffixes have
+// entity names that differ only in case or whitespace, for example
+// "GitHub" and "Github", and likely belong to the same entity.
+type DuplicateEntityWarning struct {
+	First  Suffixes
+	Second Suffixes
+}
+
+func (e DuplicateEntityWarning) Error() string {
+	return fmt.Sprintf("entity name %q at %s differs only in case or whitespace from %q at %s", e.Second.Entity, e.Second.LocationString(), e.First.Entity, e.First.LocationString())
+}
+
+func (e DuplicateEntityWarning) Location() Source {
+	return e.Second.Source
+}
+
+func (e DuplicateEntityWarning) IsWarning() bool {
+	return true
+}
+
+// RoleEmailAddressWarning reports that a block of suffixes has a
+// submitter email address that looks like a role address, such as
+// "info@example.com", rather than one that reaches a person.
+type RoleEmailAddressWarning struct {
+	Suffixes Suffixes
+	Address  *mail.Address
+}
+
+func (e RoleEmailAddressWarning) Error() string {
+	return fmt.Sprintf("submitter address %q for %s is a role address", e.Address.Address, e.Suffixes.shortName())
+}
+
+func (e RoleEmailAddressWarning) Location() Source {
+	return e.Suffixes.Source
+}
+
+func (e RoleEmailAddressWarning) IsWarning() bool {
+	return true
+}
+
+// EmailMissingDisplayNameError reports that the submitter of a
+// private domains suffix block is given only as an email address,
+// with no person's name.
+type EmailMissingDisplayNameError struct {
+	Suffixes Suffixes
+	Address  *mail.Address
+}
+
+func (e EmailMissingDisplayNameError) Error() string {
+	return fmt.Sprintf("submitter %q for %s at %s has no name, contact information must include a person's name", e.Address.Address, e.Suffixes.shortName(), e.Suffixes.LocationString())
+}
+
+func (e EmailMissingDisplayNameError) Location() Source {
+	return e.Suffixes.Source
+}
+
+// EmailMissingDisplayNameWarning is like EmailMissingDisplayNameError,
+// for blocks outside the private domains section.
+type EmailMissingDisplayNameWarning struct {
+	Suffixes Suffixes
+	Address  *mail.Address
+}
+
+func (e EmailMissingDisplayNameWarning) Error() string {
+	return fmt.Sprintf("submitter %q for %s at %s has no name", e.Address.Address, e.Suffixes.shortName(), e.Suffixes.LocationString())
+}
+
+func (e EmailMissingDisplayNameWarning) Location() Source {
+	return e.Suffixes.Source
+}
+
+func (e EmailMissingDisplayNameWarning) IsWarning() bool {
+	return true
+}
+
+// DisallowedIDNACharacterError reports that an internationalized
+// label of a suffix entry contains a code point that IDNA2008 does
+// not allow in registered domain names.
+type DisallowedIDNACharacterError struct {
+	Line  Source
+	Label string
+	// CodePoint is a best guess at the disallowed code point in
+	// Label: the first code point that is not a valid registration
+	// on its own. It may be a code point that is only invalid in
+	// isolation, such as a combining mark, and is 0 if every code
+	// point is valid on its own but Label is invalid as a whole, for
+	// example due to a contextual rule. Err has the authoritative
+	// reason.
+	CodePoint rune
+	Err       error
+}
+
+func (e DisallowedIDNACharacterError) Error() string {
+	if e.CodePoint == 0 {
+		return fmt.Sprintf("label %q of suffix %q at %s is not a valid IDNA2008 registration: %v", e.Label, e.Line.Raw, e.Line.LocationString(), e.Err)
+	}
+	return fmt.Sprintf("label %q of suffix %q at %s contains disallowed code point %U: %v", e.Label, e.Line.Raw, e.Line.LocationString(), e.CodePoint, e.Err)
+}
+
+func (e DisallowedIDNACharacterError) Location() Source {
+	return e.Line
+}
+
+// NonNormalizedEntityNameError reports that a block of suffixes has
+// an entity name that is not in Unicode Normalization Form C.
+type NonNormalizedEntityNameError struct {
+	Suffixes Suffixes
+}
+
+func (e NonNormalizedEntityNameError) Error() string {
+	return fmt.Sprintf("entity name %q at %s is not NFC-normalized", e.Suffixes.Entity, e.Suffixes.LocationString())
+}
+
+func (e NonNormalizedEntityNameError) Location() Source {
+	return e.Suffixes.Source
+}
+
+// OverlappingWildcardsWarning reports that a wildcard suffix entry
+// is within the coverage of another wildcard entry, for example
+// "*.compute.example.com" and "*.example.com".
+type OverlappingWildcardsWarning struct {
+	Outer Source
+	Inner Source
+}
+
+func (e OverlappingWildcardsWarning) Error() string {
+	return fmt.Sprintf("wildcard %q at %s overlaps with wildcard %q at %s", e.Inner.Raw, e.Inner.LocationString(), e.Outer.Raw, e.Outer.LocationString())
+}
+
+func (e OverlappingWildcardsWarning) Location() Source {
+	return e.Inner
+}
+
+func (e OverlappingWildcardsWarning) IsWarning() bool {
+	return true
+}
+
+// WildcardWithNoExceptionsWarning reports a wildcard suffix entry
+// that has no exception rules.
+type WildcardWithNoExceptionsWarning struct {
+	Wildcard Source
+}
+
+func (e WildcardWithNoExceptionsWarning) Error() string {
+	return fmt.Sprintf("wildcard %q at %s has no exceptions", e.Wildcard.Raw, e.Wildcard.LocationString())
+}
+
+func (e WildcardWithNoExceptionsWarning) Location() Source {
+	return e.Wildcard
+}
+
+func (e WildcardWithNoExceptionsWarning) IsWarning() bool {
+	return true
+}
+
+// CrossBlockDuplicateSuffixError reports that the same suffix is
+// listed in two different blocks of suffixes.
+type CrossBlockDuplicateSuffixError struct {
+	First  Suffixes
+	Second Suffixes
+	// Suffix is the duplicated suffix, in lowercase.
+	Suffix string
+}
+
+func (e CrossBlockDuplicateSuffixError) Error() string {
+	return fmt.Sprintf("suffix %q in %s at %s is also listed in %s at %s", e.Suffix, e.Second.shortName(), e.Second.LocationString(), e.First.shortName(), e.First.LocationString())
+}
+
+func (e CrossBlockDuplicateSuffixError) Location() Source {
+	for _, entry := range e.Second.Entries {
+		if strings.ToLower(entry.Raw) == e.Suffix {
+			return entry
+		}
+	}
+	return e.Second.Source
+}
+
+// NormalizedDuplicateError reports that two suffix entries are
+// written differently, but are the same suffix once normalized, e.g.
+// "公司.example" and "xn--55qx5d.example".
+type NormalizedDuplicateError struct {
+	First  Source
+	Second Source
+	// Normalized is the normalized form of both entries.
+	Normalized string
+}
+
+func (e NormalizedDuplicateError) Error() string {
+	return fmt.Sprintf("suffix %q at %s is a duplicate of %q at %s, both normalize to %q", e.Second.Raw, e.Second.LocationString(), e.First.Raw, e.First.LocationString(), e.Normalized)
+}
+
+func (e NormalizedDuplicateError) Location() Source {
+	return e.Second
+}
+
+// ProbableWrongSectionError reports that a block of suffixes in the
+// private domains section looks like it belongs in the ICANN
+// section.
+type ProbableWrongSectionError struct {
+	Suffixes Suffixes
+	// Reason explains why the block looks misfiled.
+	Reason string
+}
+
+func (e ProbableWrongSectionError) Error() string {
+	return fmt.Sprintf("%s at %s may belong in the ICANN section: %s", e.Suffixes.shortName(), e.Suffixes.LocationString(), e.Reason)
+}
+
+func (e ProbableWrongSectionError) Location() Source {
+	return e.Suffixes.Source
+}
+
+// EmptyCommentWarning reports a comment block whose lines are all
+// empty once the "//" prefix is removed.
+type EmptyCommentWarning struct {
+	Comment Comment
+}
+
+func (e EmptyCommentWarning) Error() string {
+	return fmt.Sprintf("comment block at %s has no text", e.Comment.LocationString())
+}
+
+func (e EmptyCommentWarning) Location() Source {
+	return e.Comment.Source
+}
+
+func (e EmptyCommentWarning) IsWarning() bool {
+	return true
+}
+
+// SingleLineCommentBeforeSuffixBlock reports a single-line comment
+// with no meaningful text, such as "//" or "// -", right before a
+// block of suffixes. It is usually left over from a deleted comment.
+type SingleLineCommentBeforeSuffixBlock struct {
+	Comment  Comment
+	Suffixes Suffixes
+}
+
+func (e SingleLineCommentBeforeSuffixBlock) Error() string {
+	return fmt.Sprintf("comment %q at %s before %s has no text, and may be left over from a deleted comment", e.Comment.Raw, e.Comment.LocationString(), e.Suffixes.shortName())
+}
+
+func (e SingleLineCommentBeforeSuffixBlock) Location() Source {
+	return e.Comment.Source
+}
+
+// UnicodeReplacementCharacterError reports a line that contains the
+// Unicode replacement character U+FFFD, or bytes that are not valid
+// UTF-8. Either indicates that the text went through a lossy encoding
+// conversion at some point.
+type UnicodeReplacementCharacterError struct {
+	Line Source
+}
+
+func (e UnicodeReplacementCharacterError) Error() string {
+	return fmt.Sprintf("line %q at %s contains the Unicode replacement character or invalid UTF-8, the original text may have been lost in an encoding conversion", e.Line.Raw, e.Line.LocationString())
+}
+
+func (e UnicodeReplacementCharacterError) Location() Source {
+	return e.Line
+}
+
+// ControlCharacterError reports a line that contains an ASCII control
+// character other than tab.
+type ControlCharacterError struct {
+	Line Source
+	Char rune
+}
+
+func (e ControlCharacterError) Error() string {
+	return fmt.Sprintf("line %q at %s contains control character %U", e.Line.Raw, e.Line.LocationString(), e.Char)
+}
+
+func (e ControlCharacterError) Location() Source {
+	return e.Line
+}
+
+// TabCharacterError reports a line that contains a tab character.
+type TabCharacterError struct {
+	Line Source
+}
+
+func (e TabCharacterError) Error() string {
+	return fmt.Sprintf("line %q at %s contains a tab, use spaces instead", e.Line.Raw, e.Line.LocationString())
+}
+
+func (e TabCharacterError) Location() Source {
+	return e.Line
+}
+
+// UnusualWhitespaceError reports a line that contains a whitespace
+// character other than space or tab, such as a non-breaking space.
+type UnusualWhitespaceError struct {
+	Line Source
+	Char rune
+}
+
+func (e UnusualWhitespaceError) Error() string {
+	return fmt.Sprintf("line %q at %s contains unusual whitespace character %U, use spaces instead", e.Line.Raw, e.Line.LocationString(), e.Char)
+}
+
+func (e UnusualWhitespaceError) Location() Source {
+	return e.Line
+}
+
+// EmptySectionError reports that a file section contains no suffix
+// blocks.
+type EmptySectionError struct {
+	Start StartSection
+}
+
+func (e EmptySectionError) Error() string {
+	return fmt.Sprintf("section %q started at %s contains no suffixes", e.Start.Name, e.Start.LocationString())
+}
+
+func (e EmptySectionError) Location() Source {
+	return e.Start.Source
+}
+
+// UnusualICANNDepthWarning reports that a suffix entry in the ICANN
+// section is a public suffix with three or more labels, for example
+// "city.example.jp" or "*.example.jp". Most ICANN suffixes are a TLD,
+// or a registry-managed second-level domain such as "co.uk".
+type UnusualICANNDepthWarning struct {
+	Line Source
+	// Depth is the number of labels in the public suffix, including
+	// any wildcard label.
+	Depth int
+}
+
+func (e UnusualICANNDepthWarning) Error() string {
+	return fmt.Sprintf("ICANN suffix %q at %s has %d labels, which is unusual for an ICANN suffix", e.Line.Raw, e.Line.LocationString(), e.Depth)
+}
+
+func (e UnusualICANNDepthWarning) Location() Source {
+	return e.Line
+}
+
+func (e UnusualICANNDepthWarning) IsWarning() bool {
+	return true
+}
+
+// PrivateSuffixMasksIANATLDError reports that a suffix entry in the
+// private domains section is an IANA top-level domain, or a wildcard
+// directly under one. Such an entry would make every domain under
+// the TLD a private suffix.
+type PrivateSuffixMasksIANATLDError struct {
+	Suffixes Suffixes
+	Line     Source
+	TLD      string
+}
+
+func (e PrivateSuffixMasksIANATLDError) Error() string {
+	return fmt.Sprintf("private suffix %q for %s at %s covers the entire IANA TLD %q", e.Line.Raw, e.Suffixes.shortName(), e.Line.LocationString(), e.TLD)
+}
+
+func (e PrivateSuffixMasksIANATLDError) Location() Source {
+	return e.Line
+}
+
+// MissingRequiredEntityError reports that a file has no suffix block
+// for an entity that it is required to have.
+type MissingRequiredEntityError struct {
+	Name string
+}
+
+func (e MissingRequiredEntityError) Error() string {
+	return fmt.Sprintf("required entity %q has no suffix block", e.Name)
+}
+
+// FileError is an error found while parsing one of several files
+// passed to ParseAll.
+type FileError struct {
+	Filename string
+	Err      error
+}
+
+func (e FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Filename, e.Err)
+}
+
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// CrossFileDuplicateError reports that the same suffix is listed in
+// two of the files passed to ParseAll.
+type CrossFileDuplicateError struct {
+	FirstFile  string
+	First      Source
+	SecondFile string
+	Second     Source
+}
+
+func (e CrossFileDuplicateError) Error() string {
+	return fmt.Sprintf("suffix %q in %s at %s is already listed in %s at %s", e.Second.Raw, e.SecondFile, e.Second.LocationString(), e.FirstFile, e.First.LocationString())
+}
+
+func (e CrossFileDuplicateError) Location() Source {
+	return e.Second
+}
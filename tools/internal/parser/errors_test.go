@@ -0,0 +1,54 @@
+package parser
+
+import "testing"
+
+func TestFixit(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // Example Corp
+      Example.COM
+
+      //===BEGIN SLOPPY DOMAINS===
+
+      // ===END ICANN DOMAINS===
+    `))
+	f2 := Parse(dedent(`
+      // Example Corp
+      .example.com
+      example.org.
+    `))
+	f3 := Parse(dedent(`
+      // ===BEGIN  PRIVATE DOMAINS===
+
+      // ===END PRIVATE DOMAINS===
+    `))
+
+	errs := append(f.Warnings, f.DetectMalformedSectionMarkers()...)
+	errs = append(errs, f2.Errors...)
+	errs = append(errs, f3.Warnings...)
+	want := []string{
+		"example.com",
+		"// ===BEGIN SLOPPY DOMAINS===",
+		"example.com",
+		"example.org",
+		"// ===BEGIN PRIVATE DOMAINS===",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(errs), len(want), errs)
+	}
+	for i, err := range errs {
+		fixer, ok := err.(Fixer)
+		if !ok {
+			t.Errorf("%T does not implement Fixer", err)
+			continue
+		}
+		if _, ok := err.(Locator); !ok {
+			t.Errorf("%T implements Fixer but not Locator", err)
+		}
+		got, ok := fixer.Fixit()
+		if !ok || got != want[i] {
+			t.Errorf("%T.Fixit() = %q, %v, want %q, true", err, got, ok, want[i])
+		}
+	}
+}
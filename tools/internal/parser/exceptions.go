@@ -24,6 +24,8 @@ func downgradeToWarning(e error) bool {
 	switch v := e.(type) {
 	case MissingEntityEmail:
 		return sourceIsExempted(missingEmail, v.Suffixes.Raw)
+	case EmailMissingDisplayNameError:
+		return sourceIsExempted(missingDisplayName, v.Suffixes.Raw)
 	}
 	return false
 }
@@ -371,3 +373,63 @@ var missingEmail = []string{
             // Submitted by Daniel Dent (https://www.danieldent.com/)
             qa2.com`),
 }
+
+// missingDisplayName are source code blocks in the private domains
+// section that are allowed to have an email contact without a
+// person's name.
+var missingDisplayName = []string{
+	dedent(`// CentralNic : http://www.centralnic.com/names/domains
+            // Submitted by registry <gavin.brown@centralnic.com>
+            za.bz
+            br.com
+            cn.com
+            de.com
+            eu.com
+            jpn.com
+            mex.com
+            ru.com
+            sa.com
+            uk.com
+            us.com
+            za.com
+            com.de
+            gb.net
+            hu.net
+            jp.net
+            se.net
+            uk.net
+            ae.org
+            com.se`),
+	dedent(`// EasyWP : https://www.easywp.com
+            // Submitted by <infracloudteam@namecheap.com>
+            *.ewp.live`),
+	dedent(`// Electromagnetic Field : https://www.emfcamp.org
+            // Submitted by <noc@emfcamp.org>
+            at.emf.camp`),
+	dedent(`// ECG Robotics, Inc: https://ecgrobotics.org
+            // Submitted by <frc1533@ecgrobotics.org>
+            onred.one
+            staging.onred.one`),
+	dedent(`// Government of the Netherlands: https://www.government.nl
+            // Submitted by <domeinnaam@minaz.nl>
+            gov.nl`),
+	dedent(`// Linode : https://linode.com
+            // Submitted by <security@linode.com>
+            members.linode.com
+            *.nodebalancer.linode.com
+            *.linodeobjects.com
+            ip.linodeusercontent.com`),
+	dedent(`// priv.at : http://www.nic.priv.at/
+            // Submitted by registry <lendl@nic.at>
+            priv.at`),
+	dedent(`// UDR Limited : http://www.udr.hk.com
+            // Submitted by registry <hostmaster@udr.hk.com>
+            hk.com
+            inc.hk
+            ltd.hk
+            hk.org`),
+	dedent(`// ZaNiC : http://www.za.net/
+            // Submitted by registry <hostmaster@nic.za.net>
+            za.net
+            za.org`),
+}
@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// csvHeader is the header row written by ExportToCSV.
+var csvHeader = []string{
+	"section",
+	"entity",
+	"url",
+	"email",
+	"suffix",
+	"is_wildcard",
+	"is_exception",
+	"start_line",
+}
+
+// ExportToCSV writes the suffix entries of f to w in CSV format, one
+// row per entry. The columns are those of csvHeader: the name of the
+// enclosing file section, the block's entity name, URL and submitter
+// email address, the entry itself, whether the entry is a wildcard or
+// exception rule, and the line on which the entry appears.
+//
+// Columns with no value, such as the URL of a block that has none,
+// are empty. If includeHeaders is true, the first row contains the
+// column names.
+func (f *File) ExportToCSV(w io.Writer, includeHeaders bool) error {
+	out := csv.NewWriter(w)
+	if includeHeaders {
+		if err := out.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+
+	var curSection string
+	for _, block := range f.Blocks {
+		switch v := block.(type) {
+		case StartSection:
+			curSection = v.Name
+		case EndSection:
+			curSection = ""
+		case Suffixes:
+			var u, email string
+			if v.URL != nil {
+				u = v.URL.String()
+			}
+			if v.Submitter != nil {
+				email = v.Submitter.Address
+			}
+			for _, entry := range v.Entries {
+				row := []string{
+					curSection,
+					v.Entity,
+					u,
+					email,
+					entry.Raw,
+					strconv.FormatBool(isWildcard(entry.Raw)),
+					strconv.FormatBool(isException(entry.Raw)),
+					strconv.Itoa(entry.StartLine),
+				}
+				if err := out.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// ExportForMozilla returns f in the flat text format consumed by
+// Mozilla and golang.org/x/net/publicsuffix: the PSL source text with
+// all comments and section markers.
+//
+// The layout of f is kept as parsed, except that within each suffix
+// block, an exception rule that is not part of its wildcard's group
+// is moved to directly follow the wildcard's group. As in the parser's
+// NonContiguousWildcardGroupWarning check, an exception is part of
+// the group if it comes after its wildcard in the same block, with no
+// ordinary suffix entry in between. Exceptions whose wildcard is in
+// another block are not moved. For a file in the canonical PSL
+// layout, the output is identical to the parsed text.
+//
+// Returns an error if f has parse errors, since such a file may not
+// be safe for downstream consumers.
+func (f *File) ExportForMozilla() ([]byte, error) {
+	if len(f.Errors) > 0 {
+		return nil, fmt.Errorf("cannot export a file with %d parse errors", len(f.Errors))
+	}
+
+	out := f.clone()
+	for i, block := range out.Blocks {
+		if suffixes, ok := block.(Suffixes); ok {
+			out.Blocks[i] = groupExceptions(suffixes)
+		}
+	}
+	return out.Marshal(), nil
+}
+
+// groupExceptions returns block with each exception rule that is
+// separated from its wildcard moved to the end of the wildcard's
+// group, if the wildcard is in block. Moved exceptions to the same
+// wildcard keep their relative order. If no exception needs to move,
+// block is returned unchanged.
+func groupExceptions(block Suffixes) Suffixes {
+	lines := strings.Split(block.Raw, "\n")
+	isEntry := func(line string) bool {
+		return !strings.HasPrefix(line, "//")
+	}
+
+	wildcards := map[string]int{}
+	for i, line := range lines {
+		if isEntry(line) && isWildcard(line) {
+			wildcards[strings.ToLower(strings.TrimPrefix(line, "*."))] = i
+		}
+	}
+	parentWildcard := func(line string) (string, bool) {
+		if !isEntry(line) || !isException(line) {
+			return "", false
+		}
+		_, parent, ok := strings.Cut(strings.ToLower(strings.TrimPrefix(line, "!")), ".")
+		_, found := wildcards[parent]
+		return parent, ok && found
+	}
+
+	// groupEnd[parent] is the index of the last line of the group
+	// for the wildcard of parent: the wildcard itself, or its last
+	// exception that is in place.
+	groupEnd := map[string]int{}
+	for parent, i := range wildcards {
+		groupEnd[parent] = i
+	}
+	moved := map[int]bool{}
+	exceptions := map[string][]string{}
+	for i, line := range lines {
+		parent, ok := parentWildcard(line)
+		if !ok {
+			continue
+		}
+		inGroup := wildcards[parent] < i && !slices.ContainsFunc(lines[wildcards[parent]+1:i], func(l string) bool {
+			return isEntry(l) && !isWildcard(l) && !isException(l)
+		})
+		if inGroup {
+			groupEnd[parent] = i
+			continue
+		}
+		moved[i] = true
+		exceptions[parent] = append(exceptions[parent], line)
+	}
+	if len(moved) == 0 {
+		return block
+	}
+
+	var ret []string
+	for i, line := range lines {
+		if moved[i] {
+			continue
+		}
+		ret = append(ret, line)
+		for parent, end := range groupEnd {
+			if end == i {
+				ret = append(ret, exceptions[parent]...)
+			}
+		}
+	}
+	return suffixesFromLines(block.StartLine, ret)
+}
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+func TestExportToCSV(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // example
+      example
+      *.wild.example
+      !www.wild.example
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Quote "Corp", Inc : https://quote.example/
+      // Submitted by Quoter <q@quote.example>
+      quote.example
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	var got strings.Builder
+	if err := f.ExportToCSV(&got, true); err != nil {
+		t.Fatalf("ExportToCSV failed: %v", err)
+	}
+	want := dedent(`
+      section,entity,url,email,suffix,is_wildcard,is_exception,start_line
+      ICANN DOMAINS,example,,,example,false,false,4
+      ICANN DOMAINS,example,,,*.wild.example,true,false,5
+      ICANN DOMAINS,example,,,!www.wild.example,false,true,6
+      PRIVATE DOMAINS,"Quote ""Corp"", Inc",https://quote.example/,q@quote.example,quote.example,false,false,13
+    `) + "\n"
+	if diff := diff.Diff(want, got.String()); diff != "" {
+		t.Errorf("unexpected CSV output (-want +got):\n%s", diff)
+	}
+
+	got.Reset()
+	if err := f.ExportToCSV(&got, false); err != nil {
+		t.Fatalf("ExportToCSV failed: %v", err)
+	}
+	if strings.HasPrefix(got.String(), "section,") {
+		t.Errorf("ExportToCSV wrote a header row with includeHeaders=false")
+	}
+}
+
+func TestExportForMozilla(t *testing.T) {
+	f := Parse(dedent(`
+      // This is the PSL.
+
+
+
+      // ===BEGIN ICANN DOMAINS===
+
+      // example
+      example
+      !www.wild.example
+      *.wild.example
+      other.example
+      !api.wild.example
+
+      // jp
+      *.kawasaki.jp
+      *.kobe.jp
+      !city.kawasaki.jp
+      !city.kobe.jp
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Example Corp : https://example.com
+      // Submitted by Example <example@example.com>
+      example.com
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	got, err := f.ExportForMozilla()
+	if err != nil {
+		t.Fatalf("ExportForMozilla() failed: %v", err)
+	}
+	want := dedent(`
+      // This is the PSL.
+
+
+
+      // ===BEGIN ICANN DOMAINS===
+
+      // example
+      example
+      *.wild.example
+      !www.wild.example
+      !api.wild.example
+      other.example
+
+      // jp
+      *.kawasaki.jp
+      *.kobe.jp
+      !city.kawasaki.jp
+      !city.kobe.jp
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Example Corp : https://example.com
+      // Submitted by Example <example@example.com>
+      example.com
+
+      // ===END PRIVATE DOMAINS===
+    `) + "\n"
+	if diff := diff.Diff(want, string(got)); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+
+	bad := Parse("// ===BEGIN ICANN DOMAINS===")
+	if _, err := bad.ExportForMozilla(); err == nil {
+		t.Error("ExportForMozilla() of file with errors did not return an error")
+	}
+
+	// The real PSL is already in the Mozilla format, and exports
+	// unchanged.
+	bs, err := os.ReadFile("../../../public_suffix_list.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = Parse(string(bs)).ExportForMozilla()
+	if err != nil {
+		t.Fatalf("ExportForMozilla() of the PSL failed: %v", err)
+	}
+	if !bytes.Equal(got, bs) {
+		t.Errorf("ExportForMozilla() of the PSL is not the PSL: %s", diff.Diff(string(bs), string(got)))
+	}
+}
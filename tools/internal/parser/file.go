@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"net/mail"
 	"net/url"
+	"regexp"
+	"slices"
+	"strings"
 )
 
 // File is a parsed PSL file.
@@ -23,9 +26,18 @@ type File struct {
 	// have validation errors, due to PSL policy changes. As long as
 	// the entries in question don't change, their preexisting
 	// validation errors are downgraded to lint warnings.
+	//
+	// Warnings also contains non-fatal lint findings, such as
+	// UppercaseSuffixWarning, that do not make the file invalid.
 	Warnings []error
 }
 
+// OK reports whether f has no errors, and so is a valid PSL file.
+// Warnings do not make a file invalid, and are ignored.
+func (f *File) OK() bool {
+	return len(f.Errors) == 0
+}
+
 // AllSuffixBlocks returns all suffix blocks in f.
 func (f *File) AllSuffixBlocks() []Suffixes {
 	var ret []Suffixes
@@ -45,6 +57,137 @@ func (f *File) AllSuffixBlocks() []Suffixes {
 func (f *File) SuffixBlocksInSection(name string) []Suffixes {
 	var ret []Suffixes
 
+	for _, block := range f.blocksInSection(name) {
+		switch v := block.(type) {
+		case Suffixes:
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+// SectionOf returns the start marker of the file section that
+// contains b, which must be one of f.Blocks or a block obtained from
+// f via a helper such as AllSuffixBlocks. Blocks are identified by
+// their source location and text.
+//
+// Returns false if b is not within any section, or if b is not in f.
+// Section markers are not within the section they start or end.
+func (f *File) SectionOf(b Block) (StartSection, bool) {
+	want := b.source()
+
+	var cur *StartSection
+	for _, block := range f.Blocks {
+		switch v := block.(type) {
+		case StartSection:
+			cur = &v
+			continue
+		case EndSection:
+			cur = nil
+			continue
+		}
+		if block.source() != want {
+			continue
+		}
+		if cur == nil {
+			return StartSection{}, false
+		}
+		return *cur, true
+	}
+	return StartSection{}, false
+}
+
+// WalkWithPath calls fn for each block of f in order, along with the
+// block's ancestors: the StartSection of the file section that
+// contains it, or no ancestors for blocks outside of any section.
+// Section markers are not within the section they start or end, so
+// they have no ancestors.
+//
+// If fn returns false for a StartSection, the blocks within that
+// section are skipped, and the walk continues with the section's
+// EndSection. The return value of fn is ignored for other blocks,
+// which contain no further blocks.
+//
+// The ancestors slice is only valid during the call to fn, and must
+// not be retained or modified.
+func WalkWithPath(f *File, fn func(node Block, ancestors []Block) bool) {
+	var (
+		ancestors []Block
+		skip      bool
+	)
+	for _, block := range f.Blocks {
+		switch v := block.(type) {
+		case StartSection:
+			skip = !fn(v, nil)
+			ancestors = append(ancestors[:0], v)
+		case EndSection:
+			skip = false
+			ancestors = ancestors[:0]
+			fn(v, nil)
+		default:
+			if !skip {
+				fn(block, ancestors)
+			}
+		}
+	}
+}
+
+// AllComments returns all comments in f, in the order they appear.
+//
+// This includes top-level Comment blocks, as well as the header and
+// inline comments of Suffixes blocks. Consecutive comment lines
+// within a suffix block are returned as a single Comment.
+func (f *File) AllComments() []Comment {
+	return commentsIn(f.Blocks)
+}
+
+// CommentsInSection is like AllComments, but only returns comments
+// within the named file section (for example, "ICANN DOMAINS" or
+// "PRIVATE DOMAINS").
+func (f *File) CommentsInSection(name string) []Comment {
+	return commentsIn(f.blocksInSection(name))
+}
+
+// commentsIn returns all the comments in blocks, as described in
+// AllComments.
+func commentsIn(blocks []Block) []Comment {
+	var ret []Comment
+
+	for _, block := range blocks {
+		switch v := block.(type) {
+		case Comment:
+			ret = append(ret, v)
+		case Suffixes:
+			ret = append(ret, joinCommentLines(v.Header)...)
+			ret = append(ret, joinCommentLines(v.InlineComments)...)
+		}
+	}
+
+	return ret
+}
+
+// joinCommentLines returns lines as Comments, merging runs of
+// consecutive lines into a single Comment.
+func joinCommentLines(lines []Source) []Comment {
+	var ret []Comment
+
+	for _, line := range lines {
+		if n := len(ret); n > 0 && ret[n-1].EndLine+1 == line.StartLine {
+			ret[n-1].EndLine = line.EndLine
+			ret[n-1].Raw += "\n" + line.Raw
+			continue
+		}
+		ret = append(ret, Comment{Source: line})
+	}
+
+	return ret
+}
+
+// blocksInSection returns all blocks within the named file section,
+// excluding the section's own StartSection and EndSection markers.
+func (f *File) blocksInSection(name string) []Block {
+	var ret []Block
+
 	var curSection string
 	for _, block := range f.Blocks {
 		switch v := block.(type) {
@@ -55,7 +198,7 @@ func (f *File) SuffixBlocksInSection(name string) []Suffixes {
 				return ret
 			}
 			curSection = ""
-		case Suffixes:
+		default:
 			if curSection == name {
 				ret = append(ret, v)
 			}
@@ -64,6 +207,261 @@ func (f *File) SuffixBlocksInSection(name string) []Suffixes {
 	return ret
 }
 
+// clone returns a copy of f that can be modified without affecting
+// f. Suffixes blocks are shared with f until modified, and must be
+// cloned by the caller before modification.
+func (f *File) clone() *File {
+	return &File{
+		Blocks:   slices.Clone(f.Blocks),
+		Errors:   slices.Clone(f.Errors),
+		Warnings: slices.Clone(f.Warnings),
+	}
+}
+
+// FindBlocksByURLPrefix returns all suffix blocks in f whose URL
+// starts with urlPrefix, for example "https://aws.amazon.com".
+//
+// The comparison is done on the string form of the parsed URL. Blocks
+// without a URL never match.
+func (f *File) FindBlocksByURLPrefix(urlPrefix string) []Suffixes {
+	var ret []Suffixes
+
+	for _, block := range f.AllSuffixBlocks() {
+		if block.URL == nil {
+			continue
+		}
+		if strings.HasPrefix(block.URL.String(), urlPrefix) {
+			ret = append(ret, block)
+		}
+	}
+
+	return ret
+}
+
+// FindBlocksByRegex returns all suffix blocks in f whose URL matches
+// the regular expression pattern. The pattern is matched against the
+// full string form of the URL, and is unanchored unless pattern
+// specifies otherwise. Blocks without a URL never match.
+//
+// Returns an error if pattern is not a valid regular expression.
+func (f *File) FindBlocksByRegex(pattern string) ([]Suffixes, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []Suffixes
+	for _, block := range f.AllSuffixBlocks() {
+		if block.URL == nil {
+			continue
+		}
+		if re.MatchString(block.URL.String()) {
+			ret = append(ret, block)
+		}
+	}
+
+	return ret, nil
+}
+
+// URLs returns the URLs of all suffix blocks in f, in the order they
+// first appear. URLs that appear in several blocks are returned only
+// once. Use FindBlocksByURLPrefix to find the blocks that reference a
+// given URL.
+func (f *File) URLs() []*url.URL {
+	var ret []*url.URL
+
+	seen := map[string]bool{}
+	for _, block := range f.AllSuffixBlocks() {
+		if block.URL == nil {
+			continue
+		}
+		u := block.URL.String()
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		ret = append(ret, block.URL)
+	}
+
+	return ret
+}
+
+// SuffixStrings returns the text of every suffix entry in f, in the
+// order they appear, for example "example.com", "*.example.net" or
+// "!www.example.net". Entries from all file sections are included.
+// This is the plain list format accepted by many public suffix
+// libraries.
+func (f *File) SuffixStrings() []string {
+	var ret []string
+	for _, block := range f.AllSuffixBlocks() {
+		for _, entry := range block.Entries {
+			ret = append(ret, entry.Raw)
+		}
+	}
+	return ret
+}
+
+// CountBlocksBySection returns the number of suffix blocks in each
+// file section of f, keyed by section name. Suffix blocks outside of
+// any section are counted under the empty name.
+func (f *File) CountBlocksBySection() map[string]int {
+	ret := map[string]int{}
+	f.forEachSuffixBlock(func(section string, block Suffixes) {
+		ret[section]++
+	})
+	return ret
+}
+
+// CountSuffixesBySection returns the number of suffix entries in
+// each file section of f, keyed by section name. Suffix entries
+// outside of any section are counted under the empty name.
+func (f *File) CountSuffixesBySection() map[string]int {
+	ret := map[string]int{}
+	f.forEachSuffixBlock(func(section string, block Suffixes) {
+		ret[section] += len(block.Entries)
+	})
+	return ret
+}
+
+// forEachSuffixBlock calls fn for each suffix block in f, along with
+// the name of the file section that contains it, or the empty string
+// for blocks outside of any section.
+func (f *File) forEachSuffixBlock(fn func(section string, block Suffixes)) {
+	var curSection string
+	for _, block := range f.Blocks {
+		switch v := block.(type) {
+		case StartSection:
+			curSection = v.Name
+		case EndSection:
+			curSection = ""
+		case Suffixes:
+			fn(curSection, v)
+		}
+	}
+}
+
+// CountWildcardsWithExceptions returns the number of wildcard
+// suffixes in f (for example "*.example.com") that have at least one
+// exception rule (for example "!www.example.com") anywhere in f.
+func (f *File) CountWildcardsWithExceptions() int {
+	ret := 0
+	for _, w := range f.wildcardExceptions() {
+		if len(w.Exceptions) > 0 {
+			ret++
+		}
+	}
+	return ret
+}
+
+// CountWildcardsWithoutExceptions returns the number of wildcard
+// suffixes in f that have no exception rules.
+func (f *File) CountWildcardsWithoutExceptions() int {
+	ret := 0
+	for _, w := range f.wildcardExceptions() {
+		if len(w.Exceptions) == 0 {
+			ret++
+		}
+	}
+	return ret
+}
+
+// WildcardToExceptionRatio returns the ratio of wildcard suffixes to
+// exception rules in f. Returns 0 if f has no exception rules.
+func (f *File) WildcardToExceptionRatio() float64 {
+	wildcards, exceptions := 0, 0
+	for _, block := range f.AllSuffixBlocks() {
+		for _, entry := range block.Entries {
+			switch {
+			case isWildcard(entry.Raw):
+				wildcards++
+			case isException(entry.Raw):
+				exceptions++
+			}
+		}
+	}
+	if exceptions == 0 {
+		return 0
+	}
+	return float64(wildcards) / float64(exceptions)
+}
+
+// WildcardExceptions is a wildcard suffix entry and the exception
+// rules that carve holes in it.
+type WildcardExceptions struct {
+	// Wildcard is the wildcard entry, for example "*.example.com".
+	Wildcard Source
+	// Exceptions are the exception entries for Wildcard, for example
+	// "!www.example.com", in the order they appear in the file.
+	Exceptions []Source
+}
+
+// DetectSuffixesWithWildcardExceptions returns the wildcard suffixes
+// in f that have at least minCount exception rules, along with their
+// exceptions. A wildcard with a long list of exceptions may be better
+// written as an explicit list of suffixes, so these are worth a
+// second look from reviewers. 10 is a reasonable minCount.
+func (f *File) DetectSuffixesWithWildcardExceptions(minCount int) []WildcardExceptions {
+	var ret []WildcardExceptions
+	for _, w := range f.wildcardExceptions() {
+		if len(w.Exceptions) >= minCount {
+			ret = append(ret, w)
+		}
+	}
+	return ret
+}
+
+// wildcardExceptions returns all the wildcard suffixes in f, in file
+// order, with the exception rules that carve holes in each wildcard.
+// If a wildcard appears more than once, only its first appearance is
+// returned.
+//
+// In the PSL, exceptions do not need to directly follow their
+// wildcard, so the entire file is searched.
+func (f *File) wildcardExceptions() []WildcardExceptions {
+	var ret []WildcardExceptions
+	// byDomain maps the domain a wildcard applies to, for example
+	// "example.com" for the rule "*.example.com", to its index in
+	// ret.
+	byDomain := map[string]int{}
+	var exceptions []Source
+	for _, block := range f.AllSuffixBlocks() {
+		for _, entry := range block.Entries {
+			switch {
+			case isWildcard(entry.Raw):
+				wildcard := strings.TrimPrefix(entry.Raw, "*.")
+				if _, ok := byDomain[wildcard]; !ok {
+					byDomain[wildcard] = len(ret)
+					ret = append(ret, WildcardExceptions{Wildcard: entry})
+				}
+			case isException(entry.Raw):
+				exceptions = append(exceptions, entry)
+			}
+		}
+	}
+	for _, exc := range exceptions {
+		_, parent, ok := strings.Cut(strings.TrimPrefix(exc.Raw, "!"), ".")
+		if !ok {
+			continue
+		}
+		if i, ok := byDomain[parent]; ok {
+			ret[i].Exceptions = append(ret[i].Exceptions, exc)
+		}
+	}
+	return ret
+}
+
+// isWildcard reports whether the suffix entry is a wildcard rule, for
+// example "*.example.com".
+func isWildcard(entry string) bool {
+	return strings.HasPrefix(entry, "*.")
+}
+
+// isException reports whether the suffix entry is an exception rule,
+// for example "!www.example.com".
+func isException(entry string) bool {
+	return strings.HasPrefix(entry, "!")
+}
+
 // Source is a piece of source text with location information.
 type Source struct {
 	// StartLine is the first line of this piece of source text in the
@@ -88,6 +486,24 @@ func (s Source) LocationString() string {
 	return fmt.Sprintf("lines %d-%d", s.StartLine, s.EndLine)
 }
 
+// Contains reports whether every line of o is also a line of s.
+//
+// Line ranges are inclusive at both ends, as with StartLine and
+// EndLine: a single-line Source has StartLine == EndLine, and a
+// Source contains itself.
+func (s Source) Contains(o Source) bool {
+	return s.StartLine <= o.StartLine && o.EndLine <= s.EndLine
+}
+
+// Overlaps reports whether s and o have at least one line in common.
+//
+// Line ranges are inclusive at both ends, so two sources that merely
+// touch (one's EndLine is the other's StartLine) overlap, while
+// sources on adjacent lines do not.
+func (s Source) Overlaps(o Source) bool {
+	return s.StartLine <= o.EndLine && o.StartLine <= s.EndLine
+}
+
 // A Block is a parsed chunk of a PSL file.
 // In Parse's output, a Block is one of the following concrete types:
 // Comment, StartSection, EndSection, Suffixes.
@@ -194,6 +610,28 @@ type Suffixes struct {
 
 func (s Suffixes) source() Source { return s.Source }
 
+// Clone returns a deep copy of s. The copy shares no memory with s,
+// so either can be modified without affecting the other.
+func (s Suffixes) Clone() Suffixes {
+	ret := s
+	ret.Header = slices.Clone(s.Header)
+	ret.Entries = slices.Clone(s.Entries)
+	ret.InlineComments = slices.Clone(s.InlineComments)
+	if s.URL != nil {
+		u := *s.URL
+		if u.User != nil {
+			user := *u.User
+			u.User = &user
+		}
+		ret.URL = &u
+	}
+	if s.Submitter != nil {
+		submitter := *s.Submitter
+		ret.Submitter = &submitter
+	}
+	return ret
+}
+
 // shortName returns either the quoted name of the responsible Entity,
 // or a generic descriptor of this suffix block if Entity is unset.
 func (s Suffixes) shortName() string {
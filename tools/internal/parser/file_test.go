@@ -0,0 +1,440 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+// testFile is a small PSL file used to exercise the File helper
+// methods.
+var testFile = dedent(`
+  // ===BEGIN ICANN DOMAINS===
+
+  // example : https://www.iana.org/domains/root/db/example.html
+  example
+
+  // ===END ICANN DOMAINS===
+  // ===BEGIN PRIVATE DOMAINS===
+
+  // AWS Thing : https://aws.amazon.com/thing
+  // Submitted by AWS Security <psl-maintainers@amazon.com>
+  thing.example
+
+  // AWS Other Thing : https://aws.amazon.com/other
+  // Submitted by AWS Security <psl-maintainers@amazon.com>
+  other.example
+
+  // DuckCorp Inc : https://duck.example.org/
+  // Submitted by Not A Duck <duck@example.com>
+  duck.example
+
+  // No URL Corp
+  // Submitted by Nobody <nobody@example.com>
+  nourl.example
+
+  // ===END PRIVATE DOMAINS===
+`)
+
+// entities returns the entity names of blocks.
+func entities(blocks []Suffixes) []string {
+	var ret []string
+	for _, b := range blocks {
+		ret = append(ret, b.Entity)
+	}
+	return ret
+}
+
+func TestSectionOf(t *testing.T) {
+	f := Parse("// Top comment.\n\n" + testFile)
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	var got []string
+	for _, block := range f.Blocks {
+		if sec, ok := f.SectionOf(block); ok {
+			got = append(got, sec.Name)
+		} else {
+			got = append(got, "-")
+		}
+	}
+	want := []string{
+		"-",               // top comment
+		"-",               // ICANN start
+		"ICANN DOMAINS",   // example
+		"-",               // ICANN end
+		"-",               // private start
+		"PRIVATE DOMAINS", // AWS Thing
+		"PRIVATE DOMAINS", // AWS Other Thing
+		"PRIVATE DOMAINS", // DuckCorp Inc
+		"PRIVATE DOMAINS", // No URL Corp
+		"-",               // private end
+	}
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("SectionOf() wrong result (-want +got):\n%s", diff)
+	}
+
+	other := Parse("// Not in f\nnot.example")
+	if _, ok := f.SectionOf(other.Blocks[0]); ok {
+		t.Errorf("SectionOf() found a section for a block not in f")
+	}
+
+	edited := f.Blocks[2].(Suffixes)
+	edited.Raw += "\nedited.example"
+	if _, ok := f.SectionOf(edited); ok {
+		t.Errorf("SectionOf() found a section for an edited copy of a block in f")
+	}
+}
+
+func TestWalkWithPath(t *testing.T) {
+	f := Parse("// Top comment.\n\n" + testFile)
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	// describe returns a short description of a visited block and
+	// its ancestors.
+	describe := func(node Block, ancestors []Block) string {
+		var ret string
+		switch v := node.(type) {
+		case Comment:
+			ret = "comment"
+		case StartSection:
+			ret = "start " + v.Name
+		case EndSection:
+			ret = "end " + v.Name
+		case Suffixes:
+			ret = v.Entity
+		}
+		for _, a := range ancestors {
+			ret += " < " + a.(StartSection).Name
+		}
+		return ret
+	}
+
+	var got []string
+	WalkWithPath(f, func(node Block, ancestors []Block) bool {
+		got = append(got, describe(node, ancestors))
+		return true
+	})
+	want := []string{
+		"comment",
+		"start ICANN DOMAINS",
+		"example < ICANN DOMAINS",
+		"end ICANN DOMAINS",
+		"start PRIVATE DOMAINS",
+		"AWS Thing < PRIVATE DOMAINS",
+		"AWS Other Thing < PRIVATE DOMAINS",
+		"DuckCorp Inc < PRIVATE DOMAINS",
+		"No URL Corp < PRIVATE DOMAINS",
+		"end PRIVATE DOMAINS",
+	}
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("WalkWithPath() visited wrong blocks (-want +got):\n%s", diff)
+	}
+
+	// Returning false for a section start skips the section's
+	// contents.
+	got = nil
+	WalkWithPath(f, func(node Block, ancestors []Block) bool {
+		got = append(got, describe(node, ancestors))
+		start, ok := node.(StartSection)
+		return !ok || start.Name != "PRIVATE DOMAINS"
+	})
+	want = []string{
+		"comment",
+		"start ICANN DOMAINS",
+		"example < ICANN DOMAINS",
+		"end ICANN DOMAINS",
+		"start PRIVATE DOMAINS",
+		"end PRIVATE DOMAINS",
+	}
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("WalkWithPath() with skipped section visited wrong blocks (-want +got):\n%s", diff)
+	}
+}
+
+func TestFindBlocksByURLPrefix(t *testing.T) {
+	f := Parse(testFile)
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"https://aws.amazon.com", []string{"AWS Thing", "AWS Other Thing"}},
+		{"https://aws.amazon.com/other", []string{"AWS Other Thing"}},
+		{"https://duck.", []string{"DuckCorp Inc"}},
+		{"https://nope.example", nil},
+		{"", []string{"example", "AWS Thing", "AWS Other Thing", "DuckCorp Inc"}},
+	}
+
+	for _, test := range tests {
+		got := entities(f.FindBlocksByURLPrefix(test.prefix))
+		if diff := diff.Diff(test.want, got); diff != "" {
+			t.Errorf("FindBlocksByURLPrefix(%q) wrong result (-want +got):\n%s", test.prefix, diff)
+		}
+	}
+}
+
+func TestFindBlocksByRegex(t *testing.T) {
+	f := Parse(testFile)
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`amazon\.com/(thing|other)$`, []string{"AWS Thing", "AWS Other Thing"}},
+		{`\.org/$`, []string{"DuckCorp Inc"}},
+		{`^http://`, nil},
+	}
+
+	for _, test := range tests {
+		blocks, err := f.FindBlocksByRegex(test.pattern)
+		if err != nil {
+			t.Errorf("FindBlocksByRegex(%q) failed: %v", test.pattern, err)
+			continue
+		}
+		got := entities(blocks)
+		if diff := diff.Diff(test.want, got); diff != "" {
+			t.Errorf("FindBlocksByRegex(%q) wrong result (-want +got):\n%s", test.pattern, diff)
+		}
+	}
+
+	if _, err := f.FindBlocksByRegex(`(unclosed`); err == nil {
+		t.Error("FindBlocksByRegex with invalid pattern did not return an error")
+	}
+}
+
+func TestURLs(t *testing.T) {
+	// Add a block with a duplicate URL at the end of the private
+	// domains section.
+	psl := strings.Replace(testFile, "// ===END PRIVATE DOMAINS===", dedent(`
+      // AWS Duplicate : https://aws.amazon.com/thing
+      // Submitted by AWS Security <psl-maintainers@amazon.com>
+      dup.example
+
+      // ===END PRIVATE DOMAINS===
+    `), 1)
+	f := Parse(psl)
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	var got []string
+	for _, u := range f.URLs() {
+		got = append(got, u.String())
+	}
+	want := []string{
+		"https://www.iana.org/domains/root/db/example.html",
+		"https://aws.amazon.com/thing",
+		"https://aws.amazon.com/other",
+		"https://duck.example.org/",
+	}
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("URLs() wrong result (-want +got):\n%s", diff)
+	}
+}
+
+func TestSuffixStrings(t *testing.T) {
+	f := Parse(testFile + "\n\n" + dedent(`
+      // Wild Corp
+      *.wild.example
+      !www.wild.example
+    `))
+
+	want := []string{
+		"example",
+		"thing.example",
+		"other.example",
+		"duck.example",
+		"nourl.example",
+		"*.wild.example",
+		"!www.wild.example",
+	}
+	if diff := diff.Diff(want, f.SuffixStrings()); diff != "" {
+		t.Errorf("SuffixStrings() wrong result (-want +got):\n%s", diff)
+	}
+}
+
+func TestCountBySection(t *testing.T) {
+	f := Parse(testFile + "\n\n" + dedent(`
+      // Outside Corp
+      a.outside.example
+      b.outside.example
+    `))
+
+	wantBlocks := map[string]int{
+		"ICANN DOMAINS":   1,
+		"PRIVATE DOMAINS": 4,
+		"":                1,
+	}
+	if diff := diff.Diff(wantBlocks, f.CountBlocksBySection()); diff != "" {
+		t.Errorf("CountBlocksBySection() wrong result (-want +got):\n%s", diff)
+	}
+
+	wantSuffixes := map[string]int{
+		"ICANN DOMAINS":   1,
+		"PRIVATE DOMAINS": 4,
+		"":                2,
+	}
+	if diff := diff.Diff(wantSuffixes, f.CountSuffixesBySection()); diff != "" {
+		t.Errorf("CountSuffixesBySection() wrong result (-want +got):\n%s", diff)
+	}
+}
+
+func TestWildcardCounts(t *testing.T) {
+	f := Parse(dedent(`
+      // Wildcard Corp
+      *.wild.example
+      !www.wild.example
+      !mail.wild.example
+      *.lonely.example
+      *.other.example
+
+      // Faraway Exception Corp
+      !www.other.example
+      !www.unrelated.example
+    `))
+
+	if got, want := f.CountWildcardsWithExceptions(), 2; got != want {
+		t.Errorf("CountWildcardsWithExceptions() = %d, want %d", got, want)
+	}
+	if got, want := f.CountWildcardsWithoutExceptions(), 1; got != want {
+		t.Errorf("CountWildcardsWithoutExceptions() = %d, want %d", got, want)
+	}
+	if got, want := f.WildcardToExceptionRatio(), 0.75; got != want {
+		t.Errorf("WildcardToExceptionRatio() = %v, want %v", got, want)
+	}
+
+	empty := Parse("")
+	if got := empty.WildcardToExceptionRatio(); got != 0 {
+		t.Errorf("WildcardToExceptionRatio() of empty file = %v, want 0", got)
+	}
+}
+
+func TestDetectSuffixesWithWildcardExceptions(t *testing.T) {
+	f := Parse(dedent(`
+      // Wildcard Corp
+      *.wild.example
+      !www.wild.example
+      !mail.wild.example
+      *.lonely.example
+      *.other.example
+
+      // Faraway Exception Corp
+      !www.other.example
+      !ftp.wild.example
+    `))
+
+	want := []WildcardExceptions{
+		{
+			Wildcard: src(2, 2, "*.wild.example"),
+			Exceptions: []Source{
+				src(3, 3, "!www.wild.example"),
+				src(4, 4, "!mail.wild.example"),
+				src(10, 10, "!ftp.wild.example"),
+			},
+		},
+	}
+	if diff := diff.Diff(want, f.DetectSuffixesWithWildcardExceptions(2)); diff != "" {
+		t.Errorf("DetectSuffixesWithWildcardExceptions(2) wrong result (-want +got):\n%s", diff)
+	}
+
+	if got := len(f.DetectSuffixesWithWildcardExceptions(1)); got != 2 {
+		t.Errorf("DetectSuffixesWithWildcardExceptions(1) returned %d wildcards, want 2", got)
+	}
+	if got := f.DetectSuffixesWithWildcardExceptions(10); got != nil {
+		t.Errorf("DetectSuffixesWithWildcardExceptions(10) = %v, want nil", got)
+	}
+}
+
+func TestSuffixesClone(t *testing.T) {
+	f := Parse(testFile)
+	orig := f.AllSuffixBlocks()[1]
+	want := orig.Clone()
+
+	clone := orig.Clone()
+	if diff := diff.Diff(orig, clone); diff != "" {
+		t.Fatalf("clone differs from original (-orig +clone):\n%s", diff)
+	}
+
+	clone.Header[0].Raw = "// Mutated"
+	clone.Entries[0].Raw = "mutated.example"
+	clone.URL.Host = "mutated.example"
+	clone.Submitter.Address = "mutated@example.com"
+	clone.Entries = append(clone.Entries, src(99, 99, "extra.example"))
+
+	if diff := diff.Diff(want, orig); diff != "" {
+		t.Errorf("mutating clone changed original (-want +got):\n%s", diff)
+	}
+}
+
+func TestAllComments(t *testing.T) {
+	f := Parse(dedent(`
+      // Top-level comment.
+
+      // ===BEGIN ICANN DOMAINS===
+
+      // ICANN comment.
+
+      // example
+      example
+      // Inline one.
+      // Inline two.
+      other.example
+      // Inline three.
+      more.example
+
+      // ===END ICANN DOMAINS===
+    `))
+
+	wantAll := []Comment{
+		{Source: src(1, 1, "// Top-level comment.")},
+		{Source: src(5, 5, "// ICANN comment.")},
+		{Source: src(7, 7, "// example")},
+		{Source: src(9, 10, "// Inline one.\n// Inline two.")},
+		{Source: src(12, 12, "// Inline three.")},
+	}
+	if diff := diff.Diff(wantAll, f.AllComments()); diff != "" {
+		t.Errorf("AllComments() wrong result (-want +got):\n%s", diff)
+	}
+
+	if diff := diff.Diff(wantAll[1:], f.CommentsInSection("ICANN DOMAINS")); diff != "" {
+		t.Errorf("CommentsInSection() wrong result (-want +got):\n%s", diff)
+	}
+}
+
+func TestSourceContainsOverlaps(t *testing.T) {
+	tests := []struct {
+		a, b         Source
+		wantContains bool
+		wantOverlaps bool
+	}{
+		{src(1, 5, ""), src(1, 5, ""), true, true},
+		{src(1, 5, ""), src(2, 3, ""), true, true},
+		{src(2, 3, ""), src(1, 5, ""), false, true},
+		{src(1, 5, ""), src(5, 5, ""), true, true},
+		{src(1, 5, ""), src(5, 8, ""), false, true},
+		{src(5, 8, ""), src(1, 5, ""), false, true},
+		{src(1, 5, ""), src(6, 8, ""), false, false},
+		{src(6, 8, ""), src(1, 5, ""), false, false},
+		{src(3, 3, ""), src(3, 3, ""), true, true},
+	}
+
+	for _, test := range tests {
+		if got := test.a.Contains(test.b); got != test.wantContains {
+			t.Errorf("%s Contains %s = %v, want %v", test.a.LocationString(), test.b.LocationString(), got, test.wantContains)
+		}
+		if got := test.a.Overlaps(test.b); got != test.wantOverlaps {
+			t.Errorf("%s Overlaps %s = %v, want %v", test.a.LocationString(), test.b.LocationString(), got, test.wantOverlaps)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FormatErrorsGNU returns the errors and warnings of f in the GNU
+// error message format, one per line:
+//
+//	filename:line: message
+//	filename:line: warning: message
+//
+// This is the format understood by many editors and CI systems, such
+// as vim's quickfix list and Emacs's compilation mode. The line is
+// the first line of the error's Location, for errors that implement
+// Locator. Other errors, such as TooManyErrorsError, are written as
+// "filename: message". The parser does not track
+// columns, so none are written.
+func FormatErrorsGNU(filename string, f *File) string {
+	var ret strings.Builder
+	write := func(err error, prefix string) {
+		if line, ok := errorLine(err); ok {
+			fmt.Fprintf(&ret, "%s:%d: %s%v\n", filename, line, prefix, err)
+		} else {
+			fmt.Fprintf(&ret, "%s: %s%v\n", filename, prefix, err)
+		}
+	}
+	for _, err := range f.Errors {
+		write(err, "")
+	}
+	for _, err := range f.Warnings {
+		write(err, "warning: ")
+	}
+	return ret.String()
+}
+
+// errorLine returns the first line of the source that err, or an
+// error it wraps, is about.
+func errorLine(err error) (int, bool) {
+	var loc Locator
+	if !errors.As(err, &loc) {
+		return 0, false
+	}
+	return loc.Location().StartLine, true
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+func TestFormatErrorsGNU(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // Example Corp
+      Example.com
+
+      // Second Corp
+      .second.example
+      third.example.
+
+      // ===END ICANN DOMAINS===
+    `))
+
+	want := dedent(`
+      psl.dat:7: suffix ".second.example" at line 7 has a leading dot, did you mean "second.example"?
+      psl.dat:8: suffix "third.example." at line 8 has a trailing dot, did you mean "third.example"?
+      psl.dat:4: warning: suffix "Example.com" at line 4 should be lowercase: "example.com"
+    `) + "\n"
+	got := FormatErrorsGNU("psl.dat", f)
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+
+	// Errors that involve several lines are reported at the line
+	// that is wrong, not the first line mentioned.
+	f = Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // ===END PRIVATE DOMAINS===
+      // ===BEGIN ICANN DOMAINS===
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	want = dedent(`
+      psl.dat:3: new section "PRIVATE DOMAINS" started at line 3 while still in section "ICANN DOMAINS" (started at line 1), add "// ===END ICANN DOMAINS===" before it to close the earlier section
+      psl.dat:8: section "PRIVATE DOMAINS" closed at line 8 while in section "ICANN DOMAINS" (started at line 6)
+    `) + "\n"
+	got = FormatErrorsGNU("psl.dat", f)
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+
+	f = &File{Errors: []error{TooManyErrorsError{Max: 1}}}
+	want = "psl.dat: too many errors (more than 1), parsing stopped\n"
+	if got := FormatErrorsGNU("psl.dat", f); got != want {
+		t.Errorf("FormatErrorsGNU() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"strings"
+)
+
+// Match is the result of looking up a domain in a PSL file.
+type Match struct {
+	// Domain is the domain that was looked up, in the normalized
+	// form used for matching.
+	Domain string
+	// PublicSuffix is the public suffix of Domain, according to the
+	// PSL matching algorithm.
+	PublicSuffix string
+	// Rule is the suffix entry that determined PublicSuffix, for
+	// example "com", "*.example.com" or "!www.example.com". Rule is
+	// empty if no entry matched and the implicit "*" rule was used.
+	Rule string
+}
+
+// Match looks up domain in f using the PSL matching algorithm, and
+// returns the prevailing rule and the resulting public suffix.
+//
+// The algorithm is the one described at
+// https://github.com/publicsuffix/list/wiki/Format: exception rules
+// take priority over all other rules, otherwise the matching rule
+// with the most labels prevails. If no rule matches, the implicit
+// rule "*" applies, and the public suffix is the domain's final
+// label.
+//
+// A wildcard rule matches exactly one label in place of the "*". For
+// example, "*.example.com" makes "b.example.com" the public suffix
+// of both "b.example.com" and "a.b.example.com", but does not match
+// "example.com" itself.
+//
+// domain is compared case-insensitively, and must be written in the
+// same form as the PSL entries it should match (Unicode for
+// internationalized labels). A single trailing dot is ignored.
+//
+// Match builds a lookup table from f on every call. Callers doing
+// many lookups on a large file should consider caching results.
+func (f *File) Match(domain string) (Match, error) {
+	return newRuleSet(f.AllSuffixBlocks()).match(domain)
+}
+
+// RegistrableDomain returns the registrable domain of host, also
+// known as the "eTLD+1": the public suffix of host according to f,
+// plus one more label.
+//
+// Returns NoRegistrableDomainError if host is itself a public
+// suffix, and so has no registrable domain.
+func (f *File) RegistrableDomain(host string) (string, error) {
+	return newRuleSet(f.AllSuffixBlocks()).registrableDomain(host)
+}
+
+// IsPrivateSuffix reports whether the prevailing rule for domain, as
+// determined by Match, is in the "PRIVATE DOMAINS" section of f.
+//
+// Returns false if the prevailing rule is in the ICANN section, or if
+// no rule matches domain and the implicit "*" rule applies.
+func (f *File) IsPrivateSuffix(domain string) (bool, error) {
+	m, err := f.Match(domain)
+	if err != nil {
+		return false, err
+	}
+	if m.Rule == "" {
+		return false, nil
+	}
+	private := newRuleSet(f.SuffixBlocksInSection("PRIVATE DOMAINS"))
+	return private[m.Rule], nil
+}
+
+// ruleSet is the set of suffix entries of some PSL blocks, in the
+// form they appear in the file (including any "*." or "!" prefix).
+type ruleSet map[string]bool
+
+// newRuleSet returns the ruleSet for the entries in blocks.
+func newRuleSet(blocks []Suffixes) ruleSet {
+	ret := ruleSet{}
+	for _, block := range blocks {
+		for _, entry := range block.Entries {
+			ret[strings.ToLower(entry.Raw)] = true
+		}
+	}
+	return ret
+}
+
+// match implements File.Match for the given rules.
+func (rules ruleSet) match(domain string) (Match, error) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	labels := strings.Split(domain, ".")
+	for _, label := range labels {
+		if label == "" {
+			return Match{}, InvalidDomainError{Domain: domain}
+		}
+	}
+
+	ret := Match{
+		Domain:       domain,
+		PublicSuffix: labels[len(labels)-1],
+	}
+	for i := range labels {
+		suffix := strings.Join(labels[i:], ".")
+
+		// Exception rules prevail over everything else, and the
+		// public suffix is the exception minus its leftmost label.
+		if rule := "!" + suffix; rules[rule] {
+			ret.Rule = rule
+			ret.PublicSuffix = strings.Join(labels[i+1:], ".")
+			return ret, nil
+		}
+
+		// Otherwise, the first match while iterating from the
+		// longest candidate suffix to the shortest is the longest
+		// matching rule. Keep scanning only to look for exceptions.
+		if ret.Rule != "" {
+			continue
+		}
+		if rules[suffix] {
+			ret.Rule = suffix
+			ret.PublicSuffix = suffix
+		} else if i+1 < len(labels) {
+			if rule := "*." + strings.Join(labels[i+1:], "."); rules[rule] {
+				ret.Rule = rule
+				ret.PublicSuffix = suffix
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// registrableDomain implements File.RegistrableDomain for the given
+// rules.
+func (rules ruleSet) registrableDomain(host string) (string, error) {
+	m, err := rules.match(host)
+	if err != nil {
+		return "", err
+	}
+	if m.Domain == m.PublicSuffix {
+		return "", NoRegistrableDomainError{Domain: m.Domain}
+	}
+
+	rest := strings.TrimSuffix(m.Domain, "."+m.PublicSuffix)
+	if idx := strings.LastIndexByte(rest, '.'); idx >= 0 {
+		rest = rest[idx+1:]
+	}
+	return rest + "." + m.PublicSuffix, nil
+}
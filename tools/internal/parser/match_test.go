@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+// matchFile is a small PSL file used to exercise matching.
+var matchFile = dedent(`
+  // ===BEGIN ICANN DOMAINS===
+
+  // com
+  com
+
+  // jp
+  jp
+  *.kobe.jp
+  !city.kobe.jp
+
+  // ck
+  *.ck
+  !www.ck
+
+  // ===END ICANN DOMAINS===
+  // ===BEGIN PRIVATE DOMAINS===
+
+  // Example Corp : https://example.com
+  // Submitted by Example <example@example.com>
+  blogspot.com
+
+  // ===END PRIVATE DOMAINS===
+`)
+
+func TestRegistrableDomain(t *testing.T) {
+	f := Parse(matchFile)
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	tests := []struct {
+		host    string
+		want    string
+		wantErr error
+	}{
+		{"com", "", NoRegistrableDomainError{Domain: "com"}},
+		{"example.com", "example.com", nil},
+		{"www.example.com", "example.com", nil},
+		{"WWW.Example.COM.", "example.com", nil},
+		{"blogspot.com", "", NoRegistrableDomainError{Domain: "blogspot.com"}},
+		{"foo.blogspot.com", "foo.blogspot.com", nil},
+		{"a.b.foo.blogspot.com", "foo.blogspot.com", nil},
+		{"example.jp", "example.jp", nil},
+		{"c.kobe.jp", "", NoRegistrableDomainError{Domain: "c.kobe.jp"}},
+		{"b.c.kobe.jp", "b.c.kobe.jp", nil},
+		{"city.kobe.jp", "city.kobe.jp", nil},
+		{"www.city.kobe.jp", "city.kobe.jp", nil},
+		{"ck", "", NoRegistrableDomainError{Domain: "ck"}},
+		{"test.ck", "", NoRegistrableDomainError{Domain: "test.ck"}},
+		{"b.test.ck", "b.test.ck", nil},
+		{"www.ck", "www.ck", nil},
+		{"www.www.ck", "www.ck", nil},
+		// Unlisted TLD, the implicit "*" rule applies.
+		{"example.unlisted", "example.unlisted", nil},
+		{"unlisted", "", NoRegistrableDomainError{Domain: "unlisted"}},
+		{"", "", InvalidDomainError{Domain: ""}},
+		{"a..com", "", InvalidDomainError{Domain: "a..com"}},
+	}
+
+	for _, test := range tests {
+		got, err := f.RegistrableDomain(test.host)
+		if diff := diff.Diff(test.wantErr, err); diff != "" {
+			t.Errorf("RegistrableDomain(%q) wrong error (-want +got):\n%s", test.host, diff)
+		}
+		if got != test.want {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", test.host, got, test.want)
+		}
+	}
+}
+
+func TestIsPrivateSuffix(t *testing.T) {
+	f := Parse(matchFile)
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	tests := []struct {
+		domain  string
+		want    bool
+		wantErr error
+	}{
+		{"blogspot.com", true, nil},
+		{"foo.blogspot.com", true, nil},
+		{"example.com", false, nil},
+		{"com", false, nil},
+		{"www.city.kobe.jp", false, nil},
+		{"example.unlisted", false, nil},
+		{"a..com", false, InvalidDomainError{Domain: "a..com"}},
+	}
+
+	for _, test := range tests {
+		got, err := f.IsPrivateSuffix(test.domain)
+		if diff := diff.Diff(test.wantErr, err); diff != "" {
+			t.Errorf("IsPrivateSuffix(%q) wrong error (-want +got):\n%s", test.domain, diff)
+		}
+		if got != test.want {
+			t.Errorf("IsPrivateSuffix(%q) = %v, want %v", test.domain, got, test.want)
+		}
+	}
+}
+
+// TestMatchWildcardDepth checks that wildcard rules match exactly one
+// label, no matter how many labels the looked up domain has.
+func TestMatchWildcardDepth(t *testing.T) {
+	f := Parse(dedent(`
+      // com
+      com
+
+      // Example Corp
+      *.example.com
+      *.sub.example.com
+    `))
+
+	tests := []struct {
+		domain string
+		want   Match
+	}{
+		{"example.com", Match{"example.com", "com", "com"}},
+		{"b.example.com", Match{"b.example.com", "b.example.com", "*.example.com"}},
+		{"a.b.example.com", Match{"a.b.example.com", "b.example.com", "*.example.com"}},
+		{"x.a.b.example.com", Match{"x.a.b.example.com", "b.example.com", "*.example.com"}},
+		{"sub.example.com", Match{"sub.example.com", "sub.example.com", "*.example.com"}},
+		{"b.sub.example.com", Match{"b.sub.example.com", "b.sub.example.com", "*.sub.example.com"}},
+		{"a.b.sub.example.com", Match{"a.b.sub.example.com", "b.sub.example.com", "*.sub.example.com"}},
+	}
+
+	for _, test := range tests {
+		got, err := f.Match(test.domain)
+		if err != nil {
+			t.Errorf("Match(%q) failed: %v", test.domain, err)
+			continue
+		}
+		if diff := diff.Diff(test.want, got); diff != "" {
+			t.Errorf("Match(%q) wrong result (-want +got):\n%s", test.domain, diff)
+		}
+	}
+
+	regTests := []struct {
+		host    string
+		want    string
+		wantErr error
+	}{
+		{"b.example.com", "", NoRegistrableDomainError{Domain: "b.example.com"}},
+		{"a.b.example.com", "a.b.example.com", nil},
+		{"x.a.b.example.com", "a.b.example.com", nil},
+		{"a.b.sub.example.com", "a.b.sub.example.com", nil},
+	}
+	for _, test := range regTests {
+		got, err := f.RegistrableDomain(test.host)
+		if diff := diff.Diff(test.wantErr, err); diff != "" {
+			t.Errorf("RegistrableDomain(%q) wrong error (-want +got):\n%s", test.host, diff)
+		}
+		if got != test.want {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", test.host, got, test.want)
+		}
+	}
+}
+
+// TestRegistrableDomainRealList runs the PSL's shared conformance
+// tests in tests/test_psl.txt against the real list.
+func TestRegistrableDomainRealList(t *testing.T) {
+	bs, err := os.ReadFile("../../../public_suffix_list.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := Parse(string(bs))
+
+	tests, err := os.ReadFile("../../../tests/test_psl.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkRe := regexp.MustCompile(`^checkPublicSuffix\((null|'[^']*'), (null|'[^']*')\);$`)
+	for _, line := range strings.Split(string(tests), "\n") {
+		m := checkRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if m[1] == "null" {
+			continue
+		}
+		host := strings.Trim(m[1], "'")
+		want := strings.Trim(m[2], "'")
+		if m[2] == "null" {
+			want = ""
+		}
+		if strings.Contains(host, "xn--") {
+			// Matching is done on Unicode labels, punycode inputs
+			// are not converted.
+			continue
+		}
+
+		got, _ := f.RegistrableDomain(host)
+		if got != want {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"slices"
+)
+
+// MultiFile is a PSL that is maintained as several separate files,
+// which are concatenated to produce the final list.
+type MultiFile struct {
+	// Files maps each file name to its parsed contents.
+	Files map[string]*File
+}
+
+// Names returns the names of the files in m, in sorted order.
+func (m *MultiFile) Names() []string {
+	var ret []string
+	for name := range m.Files {
+		ret = append(ret, name)
+	}
+	slices.Sort(ret)
+	return ret
+}
+
+// ParseAll parses each of files, which maps file names to PSL source
+// text, and checks for suffixes that are listed in more than one
+// file.
+//
+// Parse and validation errors from each file are reported as
+// FileError, so that the file they came from can be identified.
+// Suffixes found in more than one file are reported as
+// CrossFileDuplicateError. Files are processed in sorted name order,
+// and errors are returned in that order.
+func ParseAll(files map[string]string) (*MultiFile, []error) {
+	ret := &MultiFile{
+		Files: map[string]*File{},
+	}
+	var errs []error
+
+	for name, src := range files {
+		ret.Files[name] = Parse(src)
+	}
+
+	type location struct {
+		file string
+		line Source
+	}
+	seen := map[string]location{}
+	for _, name := range ret.Names() {
+		f := ret.Files[name]
+		for _, err := range f.Errors {
+			errs = append(errs, FileError{
+				Filename: name,
+				Err:      err,
+			})
+		}
+
+		// Duplicates within a single file are that file's business,
+		// only report the first occurrence of a suffix in each
+		// file.
+		inFile := map[string]bool{}
+		for _, block := range f.AllSuffixBlocks() {
+			for _, entry := range block.Entries {
+				if inFile[entry.Raw] {
+					continue
+				}
+				inFile[entry.Raw] = true
+
+				if prev, ok := seen[entry.Raw]; ok {
+					errs = append(errs, CrossFileDuplicateError{
+						FirstFile:  prev.file,
+						First:      prev.line,
+						SecondFile: name,
+						Second:     entry,
+					})
+					continue
+				}
+				seen[entry.Raw] = location{name, entry}
+			}
+		}
+	}
+
+	return ret, errs
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+func TestParseAll(t *testing.T) {
+	files := map[string]string{
+		"a.dat": dedent(`
+          // A Corp
+          a.example
+          shared.example
+          shared.example
+        `),
+		"b.dat": dedent(`
+          // B Corp
+          b.example
+
+          // Shared Corp
+          shared.example
+        `),
+		"c.dat": dedent(`
+          // ===BEGIN BROKEN===
+        `),
+	}
+
+	m, errs := ParseAll(files)
+
+	if diff := diff.Diff([]string{"a.dat", "b.dat", "c.dat"}, m.Names()); diff != "" {
+		t.Errorf("unexpected file names (-want +got):\n%s", diff)
+	}
+
+	want := []error{
+		CrossFileDuplicateError{
+			FirstFile:  "a.dat",
+			First:      src(3, 3, "shared.example"),
+			SecondFile: "b.dat",
+			Second:     src(5, 5, "shared.example"),
+		},
+		FileError{
+			Filename: "c.dat",
+			Err: UnclosedSectionError{
+				Start: StartSection{
+					Source: src(1, 1, "// ===BEGIN BROKEN==="),
+					Name:   "BROKEN",
+				},
+			},
+		},
+	}
+	if diff := diff.Diff(want, errs); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
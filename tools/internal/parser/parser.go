@@ -2,6 +2,7 @@
 package parser
 
 import (
+	"context"
 	"net/mail"
 	"net/url"
 	"strings"
@@ -14,20 +15,93 @@ import (
 // returned File. A File with a non-empty Errors field is not a valid
 // PSL file and may contain malformed data.
 func Parse(src string) *File {
-	return parseWithExceptions(src, downgradeToWarning)
+	return ParseWithOptions(src, ParseOptions{})
 }
 
-func parseWithExceptions(src string, downgradeToWarning func(error) bool) *File {
+// Valid reports whether bs parses as a valid PSL file, with no
+// errors. Warnings are ignored, as in File.OK.
+func Valid(bs []byte) bool {
+	return Parse(string(bs)).OK()
+}
+
+// ParseOptions are optional settings that control the behavior of
+// ParseWithOptions. The zero value gives the same behavior as Parse.
+type ParseOptions struct {
+	// MaxErrors is the maximum number of errors to accumulate before
+	// giving up. Once more than MaxErrors errors have been found,
+	// parsing stops at the end of the current block and a final
+	// TooManyErrorsError is recorded. Zero means unlimited.
+	//
+	// Errors that are downgraded to warnings do not count towards
+	// the limit.
+	MaxErrors int
+
+	// CommentPrefix is the prefix that starts a comment line. Section
+	// markers use the same prefix, followed by " ===". The empty
+	// string means the canonical PSL prefix "//".
+	//
+	// This is for forks of the PSL that use a different convention
+	// in auxiliary files, for example "#". The on-demand validation
+	// methods of File and the File builders always use the canonical
+	// prefix.
+	CommentPrefix string
+
+	// ExtraValidators are additional validation passes to run after
+	// the built-in validations, for example to enforce rules that
+	// are specific to one organization. Each is given the parsed
+	// File, and the errors it returns are added to the File's Errors,
+	// except for those that implement Warning, which are added to its
+	// Warnings.
+	//
+	// As with the built-in validations, ExtraValidators only run if
+	// the file had no errors after parsing.
+	ExtraValidators []Validator
+}
+
+// Validator is a validation pass over a parsed File. It returns the
+// problems it found, or nil if there are none. Validators must not
+// modify the File.
+type Validator func(*File) []error
+
+// ParseWithOptions is like Parse, but with additional settings to
+// control parsing behavior.
+func ParseWithOptions(src string, opts ParseOptions) *File {
+	// Parsing can only fail due to context cancellation, which
+	// cannot happen with a background context.
+	f, _ := parseWithExceptions(context.Background(), src, opts, downgradeToWarning)
+	return f
+}
+
+// ParseContext is like ParseWithOptions, but stops early if ctx is
+// canceled or its deadline expires.
+//
+// ctx is checked after every parsed block and between validation
+// passes. If ctx is done before parsing completes, ParseContext
+// returns a nil File and ctx.Err().
+func ParseContext(ctx context.Context, src string, opts ParseOptions) (*File, error) {
+	return parseWithExceptions(ctx, src, opts, downgradeToWarning)
+}
+
+func parseWithExceptions(ctx context.Context, src string, opts ParseOptions, downgradeToWarning func(error) bool) (*File, error) {
 	p := parser{
+		ctx:                ctx,
+		ParseOptions:       opts,
 		downgradeToWarning: downgradeToWarning,
 	}
 	p.Parse(src)
 	p.Validate()
-	return &p.File
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &p.File, nil
 }
 
 // parser is the state for a single PSL file parse.
 type parser struct {
+	// ctx is the context for this parse. Parsing stops early if ctx
+	// is done.
+	ctx context.Context
+
 	// blockStart, if non-zero, is the line on which the current block
 	// began. The block continues until the following empty line.
 	blockStart int
@@ -51,12 +125,26 @@ type parser struct {
 	// else for testing.
 	downgradeToWarning func(error) bool
 
+	// tooManyErrors is set once more than MaxErrors errors have been
+	// recorded, and signals that parsing should stop.
+	tooManyErrors bool
+
+	// ParseOptions are the caller's settings for this parse.
+	ParseOptions
+
 	// File is the parser's output.
 	File
 }
 
 // Parse parses src as a PSL file and returns the parse result.
 func (p *parser) Parse(src string) {
+	// A byte order mark is not whitespace, so it would otherwise end
+	// up glued to the start of the first line.
+	if rest, ok := strings.CutPrefix(src, "\ufeff"); ok {
+		p.addError(ByteOrderMarkError{})
+		src = rest
+	}
+
 	lines := strings.Split(src, "\n")
 	// Add a final empty line to process, so that the block
 	// consumption logic works even if there is no final empty line in
@@ -76,6 +164,9 @@ func (p *parser) Parse(src string) {
 				p.blockEnd = i
 				p.consumeBlock()
 			}
+			if p.tooManyErrors || p.ctx.Err() != nil {
+				return
+			}
 			continue
 		}
 		if p.blockStart == 0 {
@@ -105,12 +196,20 @@ func (p *parser) consumeBlock() {
 		p.blockEnd = 0
 	}()
 
+	// In the canonical layout, section markers are separated from the
+	// section's content by an empty line. Tolerate a missing empty
+	// line with a warning, rather than misparsing the marker as part
+	// of the adjacent block.
+	if p.splitAdjacentSectionMarkers() {
+		return
+	}
+
 	// Suffix blocks are distinguished by whether or not there are any
 	// non-comment lines.
 	var header, entries, comments []Source
 	for i, l := range p.lines {
 		src := Source{p.blockStart + i, p.blockStart + i, l}
-		if !strings.HasPrefix(l, "//") {
+		if !p.isComment(l) {
 			entries = append(entries, src)
 		} else if len(entries) > 0 {
 			comments = append(comments, src)
@@ -129,6 +228,8 @@ func (p *parser) consumeBlock() {
 			InlineComments: comments,
 		}
 		p.enrichSuffixes(&s)
+		p.checkSuffixCase(s)
+		p.checkWildcardGroups(s)
 		p.addBlock(s)
 		return
 	}
@@ -164,7 +265,7 @@ func (p *parser) consumeBlock() {
 	}
 
 	for i, line := range p.lines {
-		if !strings.HasPrefix(line, sectionMarker) {
+		if !strings.HasPrefix(line, p.markerPrefix()) {
 			continue
 		}
 
@@ -191,8 +292,107 @@ func (p *parser) consumeBlock() {
 	}
 }
 
+// sectionMarker is the prefix of section marker lines in the
+// canonical PSL format.
 const sectionMarker = "// ==="
 
+// commentPrefix returns the prefix that starts comment lines in the
+// file being parsed.
+func (p *parser) commentPrefix() string {
+	if p.CommentPrefix != "" {
+		return p.CommentPrefix
+	}
+	return "//"
+}
+
+// markerPrefix returns the prefix of section marker lines in the
+// file being parsed, "// ===" by default.
+func (p *parser) markerPrefix() string {
+	return p.commentPrefix() + " ==="
+}
+
+// isComment reports whether line is a comment line.
+func (p *parser) isComment(line string) bool {
+	return strings.HasPrefix(line, p.commentPrefix())
+}
+
+// commentText removes the comment prefix and outer whitespace from
+// line.
+func (p *parser) commentText(line string) string {
+	return strings.TrimSpace(strings.TrimPrefix(line, p.commentPrefix()))
+}
+
+// splitAdjacentSectionMarkers handles a block in which section
+// markers and other lines are not separated by empty lines, for
+// example a section start directly followed by a suffix block. The
+// markers and the other lines are consumed separately, as if the
+// missing empty lines were present, and a warning is recorded for
+// each missing empty line.
+//
+// splitAdjacentSectionMarkers reports whether it consumed the block.
+// Only content that directly follows a section start or directly
+// precedes a section end is split off. Other mixes of markers and
+// content are left for consumeBlock to report.
+func (p *parser) splitAdjacentSectionMarkers() bool {
+	isMarker := func(line string) bool {
+		return strings.HasPrefix(line, p.markerPrefix())
+	}
+
+	// Split the block into runs of marker and non-marker lines, as
+	// [start, end) indexes into p.lines.
+	var runs [][2]int
+	for i, line := range p.lines {
+		if i == 0 || isMarker(line) != isMarker(p.lines[i-1]) {
+			runs = append(runs, [2]int{i, i})
+		}
+		runs[len(runs)-1][1] = i + 1
+	}
+	if len(runs) == 1 {
+		return false
+	}
+	for i, run := range runs {
+		if isMarker(p.lines[run[0]]) {
+			continue
+		}
+		if i > 0 && !strings.HasPrefix(p.lines[run[0]-1], p.markerPrefix()+"BEGIN ") {
+			return false
+		}
+		if i+1 < len(runs) && !strings.HasPrefix(p.lines[run[1]], p.markerPrefix()+"END ") {
+			return false
+		}
+	}
+
+	lines, start := p.lines, p.blockStart
+	content := -1 // index in p.Blocks of the last content block
+	for _, run := range runs {
+		if !isMarker(lines[run[0]]) {
+			p.lines = lines[run[0]:run[1]]
+			p.blockStart = start + run[0]
+			p.blockEnd = start + run[1] - 1
+			p.consumeBlock()
+			content = len(p.Blocks) - 1
+			if run[0] > 0 {
+				p.addWarning(MissingBlankAfterSectionMarkerWarning{
+					Marker: p.Blocks[content-1].(StartSection),
+					Block:  p.Blocks[content],
+				})
+			}
+			continue
+		}
+
+		for i := run[0]; i < run[1]; i++ {
+			p.consumeSectionMarker(Source{start + i, start + i, lines[i]})
+			if i == run[0] && i > 0 {
+				p.addWarning(MissingBlankBeforeSectionMarkerWarning{
+					Marker: p.Blocks[len(p.Blocks)-1].(EndSection),
+					Block:  p.Blocks[content],
+				})
+			}
+		}
+	}
+	return true
+}
+
 // consumeSectionMarker treats the given line as a section marker and
 // generates appropriate StartSection/EndSection blocks.
 //
@@ -200,13 +400,28 @@ const sectionMarker = "// ==="
 // start/end pairs, nested sections, and lines that look like section
 // markers but aren't one of the known kinds.
 func (p *parser) consumeSectionMarker(line Source) {
-	markerWithoutStart := strings.TrimPrefix(line.Raw, sectionMarker)
+	markerWithoutStart := strings.TrimPrefix(line.Raw, p.markerPrefix())
 	if markerWithoutStart == line.Raw {
 		// Somehow we got called with a line that doesn't look have
 		// the right prefix, something is very wrong.
 		panic("consumeSectionMarker called with non-marker line")
 	}
 
+	// Extra "=" characters would otherwise end up in the marker type
+	// or section name. Report them, and parse the marker as if the
+	// delimiters were correct.
+	fixed := strings.TrimLeft(markerWithoutStart, "=")
+	if text := strings.TrimRight(fixed, "="); len(fixed)-len(text) > 3 {
+		fixed = text + "==="
+	}
+	if fixed != markerWithoutStart {
+		p.addError(MalformedSectionDelimiterError{
+			Line:       line,
+			Suggestion: p.markerPrefix() + strings.TrimRight(fixed, "=") + "===",
+		})
+		markerWithoutStart = fixed
+	}
+
 	// Note hasTrailer gets used below to report an error if the
 	// trailing === is missing. We delay reporting the error so that
 	// if the entire line is invalid, we don't report both a
@@ -222,6 +437,17 @@ func (p *parser) consumeSectionMarker(line Source) {
 		markerType = ""
 	}
 
+	// Extra spaces around or within the name would make the END
+	// marker fail to match its BEGIN. Report them, and use the
+	// normalized name.
+	if normalized := strings.Join(strings.Fields(name), " "); (markerType == "BEGIN" || markerType == "END") && normalized != name {
+		p.addWarning(SectionNameWhitespaceWarning{
+			Line:       line,
+			Suggestion: p.markerPrefix() + markerType + " " + normalized + "===",
+		})
+		name = normalized
+	}
+
 	switch markerType {
 	case "BEGIN":
 		start := StartSection{
@@ -233,8 +459,9 @@ func (p *parser) consumeSectionMarker(line Source) {
 			// continue parsing as if the previous section was closed
 			// correctly before this one started.
 			p.addError(NestedSectionError{
-				Outer: *p.currentSection,
-				Inner: start,
+				Outer:      *p.currentSection,
+				Inner:      start,
+				Suggestion: p.markerPrefix() + "END " + p.currentSection.Name + "===",
 			})
 		}
 		if !hasTrailer {
@@ -253,6 +480,16 @@ func (p *parser) consumeSectionMarker(line Source) {
 			p.addError(UnstartedSectionError{
 				End: end,
 			})
+		} else if strings.EqualFold(p.currentSection.Name, name) && p.currentSection.Name != name {
+			// Same section, but the name is capitalized
+			// differently. Close the section as normal, under the
+			// name it was started with.
+			p.addWarning(SectionEndCasingError{
+				Start:      *p.currentSection,
+				End:        end,
+				Suggestion: p.markerPrefix() + "END " + p.currentSection.Name + "===",
+			})
+			end.Name = p.currentSection.Name
 		} else if p.currentSection.Name != name {
 			// Mismatched start/end.
 			p.addError(MismatchedSectionError{
@@ -291,17 +528,22 @@ func (p *parser) enrichSuffixes(suffixes *Suffixes) {
 	//
 	// If the canonical form is missing, a number of other variations
 	// are tried in order to maximize the information we can extract
-	// from the real PSL. Non-canonical representations may produce
-	// validation errors in future, but currently do not.
+	// from the real PSL. Most non-canonical representations are
+	// accepted silently, but a name line that combines a URL and an
+	// email address produces a CombinedURLEmailWarning. Others may
+	// produce validation errors in future.
 	//
 	// See splitNameish for a list of accepted alternate forms.
 	for _, line := range suffixes.Header {
-		name, url, contact := splitNameish(trimComment(line.Raw))
+		name, url, contact := splitNameish(p.commentText(line.Raw))
 		if name == "" {
 			continue
 		}
 
 		suffixes.Entity = name
+		if url != nil && contact != nil {
+			p.addWarning(CombinedURLEmailWarning{Line: line})
+		}
 		if url != nil {
 			suffixes.URL = url
 		}
@@ -313,7 +555,7 @@ func (p *parser) enrichSuffixes(suffixes *Suffixes) {
 	if suffixes.Entity == "" {
 		// Assume the first line is the entity name, if it's not
 		// obviously something else.
-		first := trimComment(suffixes.Header[0].Raw)
+		first := p.commentText(suffixes.Header[0].Raw)
 		// "see also" is the first line of a number of ICANN TLD
 		// sections.
 		if getSubmitter(first) == nil && getURL(first) == nil && first != "see also" {
@@ -327,7 +569,7 @@ func (p *parser) enrichSuffixes(suffixes *Suffixes) {
 	// email on a line by itself.
 	if suffixes.Submitter == nil {
 		for _, line := range suffixes.Header {
-			if submitter := getSubmitter(trimComment(line.Raw)); submitter != nil {
+			if submitter := getSubmitter(p.commentText(line.Raw)); submitter != nil {
 				suffixes.Submitter = submitter
 				break
 			}
@@ -335,7 +577,7 @@ func (p *parser) enrichSuffixes(suffixes *Suffixes) {
 	}
 	if suffixes.Submitter == nil {
 		for _, line := range suffixes.Header {
-			if submitter, err := mail.ParseAddress(trimComment(line.Raw)); err == nil {
+			if submitter, err := mail.ParseAddress(p.commentText(line.Raw)); err == nil {
 				suffixes.Submitter = submitter
 				break
 			}
@@ -347,7 +589,7 @@ func (p *parser) enrichSuffixes(suffixes *Suffixes) {
 	// itself.
 	if suffixes.URL == nil {
 		for _, line := range suffixes.Header {
-			if u := getURL(trimComment(line.Raw)); u != nil {
+			if u := getURL(p.commentText(line.Raw)); u != nil {
 				suffixes.URL = u
 				break
 			}
@@ -379,6 +621,9 @@ const submittedBy = "submitted by"
 //     instead of a regular colon.
 //   - Any amount of whitespace on either side of the colon (or
 //     fullwidth colon).
+//   - "<entity name>: <url> <email address>", with the submitter
+//     crammed onto the same line as the URL. Both are returned, and
+//     the caller should warn about the formatting.
 func splitNameish(line string) (name string, url *url.URL, submitter *mail.Address) {
 	if strings.HasPrefix(strings.ToLower(line), submittedBy) {
 		// submitted-by lines are handled separately elsewhere, and
@@ -411,6 +656,13 @@ func splitNameish(line string) (name string, url *url.URL, submitter *mail.Addre
 	name = strings.TrimSpace(name)
 	rest = strings.TrimSpace(rest)
 
+	// Check for an email after the URL before trying to parse the
+	// whole thing as a URL, because url.Parse can swallow the email
+	// into the URL's path.
+	if u, contact, ok := splitURLAndEmail(rest); ok {
+		return name, u, contact
+	}
+
 	if u := getURL(rest); u != nil {
 		return name, u, nil
 	} else if contact := getSubmitter(rest); contact != nil {
@@ -419,6 +671,28 @@ func splitNameish(line string) (name string, url *url.URL, submitter *mail.Addre
 	return "", nil, nil
 }
 
+// splitURLAndEmail tries to parse line in the form:
+//
+//	"<url> <email address>"
+//
+// where the email address is any form accepted by mail.ParseAddress.
+// It returns the information it was able to extract, or ok=false if
+// the line is not in the expected form.
+func splitURLAndEmail(line string) (u *url.URL, contact *mail.Address, ok bool) {
+	rawURL, rest, found := strings.Cut(line, " ")
+	if !found {
+		return nil, nil, false
+	}
+	if u = getURL(rawURL); u == nil {
+		return nil, nil, false
+	}
+	contact, err := mail.ParseAddress(strings.TrimSpace(rest))
+	if err != nil {
+		return nil, nil, false
+	}
+	return u, contact, true
+}
+
 // splitNameAndURLInParens tries to parse line in the form:
 //
 //	"<entity name> (<url>)"
@@ -536,6 +810,55 @@ func getSubmitter(line string) *mail.Address {
 	return nil
 }
 
+// checkSuffixCase reports a warning for every suffix entry in
+// suffixes that is not all lowercase. The canonical form of all PSL
+// entries is lowercase.
+func (p *parser) checkSuffixCase(suffixes Suffixes) {
+	for _, entry := range suffixes.Entries {
+		if lower := strings.ToLower(entry.Raw); lower != entry.Raw {
+			p.addWarning(UppercaseSuffixWarning{
+				Line:      entry,
+				Lowercase: lower,
+			})
+		}
+	}
+}
+
+// checkWildcardGroups reports a warning for every wildcard entry in
+// suffixes whose exceptions in the same block are separated from it
+// by an ordinary suffix entry. Other wildcards and exceptions may
+// appear in between, so that related wildcards can be listed together
+// followed by all their exceptions.
+func (p *parser) checkWildcardGroups(suffixes Suffixes) {
+	for i, entry := range suffixes.Entries {
+		if !isWildcard(entry.Raw) {
+			continue
+		}
+		parent := strings.ToLower(strings.TrimPrefix(entry.Raw, "*."))
+		isOwnException := func(e Source) bool {
+			_, domain, ok := strings.Cut(strings.ToLower(strings.TrimPrefix(e.Raw, "!")), ".")
+			return isException(e.Raw) && ok && domain == parent
+		}
+
+		rest := suffixes.Entries[i+1:]
+		last := -1
+		for j, e := range rest {
+			if isOwnException(e) {
+				last = j
+			}
+		}
+		for _, e := range rest[:last+1] {
+			if !isWildcard(e.Raw) && !isException(e.Raw) {
+				p.addWarning(NonContiguousWildcardGroupWarning{
+					Wildcard:  entry,
+					Separator: e,
+				})
+				break
+			}
+		}
+	}
+}
+
 // trimComment removes the leading // and outer whitespace from line.
 func trimComment(line string) string {
 	return strings.TrimSpace(strings.TrimPrefix(line, "//"))
@@ -555,14 +878,30 @@ func (p *parser) addBlock(b Block) {
 	p.File.Blocks = append(p.File.Blocks, b)
 }
 
+// addWarning records err as a non-fatal lint warning.
+func (p *parser) addWarning(err error) {
+	p.File.Warnings = append(p.File.Warnings, err)
+}
+
 // addError records err as a parse/validation error.
 //
 // If err matches a legacy exemption from current validation rules,
 // err is recorded as a non-fatal warning instead.
+//
+// If p.MaxErrors is set and err would exceed that limit, err is
+// discarded and a single TooManyErrorsError is recorded instead.
 func (p *parser) addError(err error) {
-	if p.downgradeToWarning(err) {
+	if w, ok := err.(Warning); (ok && w.IsWarning()) || p.downgradeToWarning(err) {
 		p.File.Warnings = append(p.File.Warnings, err)
-	} else {
-		p.File.Errors = append(p.File.Errors, err)
+		return
+	}
+	if p.tooManyErrors {
+		return
+	}
+	if p.MaxErrors > 0 && len(p.File.Errors) >= p.MaxErrors {
+		p.tooManyErrors = true
+		p.File.Errors = append(p.File.Errors, TooManyErrorsError{Max: p.MaxErrors})
+		return
 	}
+	p.File.Errors = append(p.File.Errors, err)
 }
@@ -3,6 +3,8 @@ package parser
 import (
 	"bytes"
 	"cmp"
+	"context"
+	"fmt"
 	"net/mail"
 	"net/url"
 	"os"
@@ -37,6 +39,31 @@ func TestParser(t *testing.T) {
 			want: File{},
 		},
 
+		{
+			name: "just_whitespace",
+			psl:  "\n  \n\t\n\n",
+			want: File{},
+		},
+
+		{
+			name: "just_bom",
+			psl:  "\ufeff",
+			want: File{
+				Errors: []error{ByteOrderMarkError{}},
+			},
+		},
+
+		{
+			name: "bom_before_content",
+			psl:  "\ufeff// Comment after a BOM.\n",
+			want: File{
+				Blocks: []Block{
+					Comment{Source: src(1, 1, "// Comment after a BOM.")},
+				},
+				Errors: []error{ByteOrderMarkError{}},
+			},
+		},
+
 		{
 			name: "just_comments",
 			psl: dedent(`
@@ -176,6 +203,7 @@ func TestParser(t *testing.T) {
 							Source: src(2, 2, "// ===BEGIN SECRET DOMAINS==="),
 							Name:   "SECRET DOMAINS",
 						},
+						Suggestion: "// ===END ICANN DOMAINS===",
 					},
 					UnstartedSectionError{
 						EndSection{
@@ -186,6 +214,116 @@ func TestParser(t *testing.T) {
 				},
 			},
 		},
+		{
+			// A missing end marker before the next section starts
+			// is reported once, and the rest of the file parses as
+			// if the end marker were present.
+			name: "missing_end_before_next_section",
+			psl: dedent(`
+              // ===BEGIN ICANN DOMAINS===
+
+              // com
+              com
+
+              // ===BEGIN PRIVATE DOMAINS===
+
+              // Example Corp : https://example.com
+              // Submitted by Example <example@example.com>
+              example.com
+
+              // ===END PRIVATE DOMAINS===
+            `),
+			want: File{
+				Blocks: []Block{
+					StartSection{
+						Source: src(1, 1, "// ===BEGIN ICANN DOMAINS==="),
+						Name:   "ICANN DOMAINS",
+					},
+					Suffixes{
+						Source:  src(3, 4, "// com\ncom"),
+						Header:  []Source{src(3, 3, "// com")},
+						Entries: []Source{src(4, 4, "com")},
+						Entity:  "com",
+					},
+					StartSection{
+						Source: src(6, 6, "// ===BEGIN PRIVATE DOMAINS==="),
+						Name:   "PRIVATE DOMAINS",
+					},
+					Suffixes{
+						Source: src(8, 10, "// Example Corp : https://example.com\n// Submitted by Example <example@example.com>\nexample.com"),
+						Header: []Source{
+							src(8, 8, "// Example Corp : https://example.com"),
+							src(9, 9, "// Submitted by Example <example@example.com>"),
+						},
+						Entries:   []Source{src(10, 10, "example.com")},
+						Entity:    "Example Corp",
+						URL:       mustURL("https://example.com"),
+						Submitter: mustEmail("Example <example@example.com>"),
+					},
+					EndSection{
+						Source: src(12, 12, "// ===END PRIVATE DOMAINS==="),
+						Name:   "PRIVATE DOMAINS",
+					},
+				},
+				Errors: []error{
+					NestedSectionError{
+						Outer: StartSection{
+							Source: src(1, 1, "// ===BEGIN ICANN DOMAINS==="),
+							Name:   "ICANN DOMAINS",
+						},
+						Inner: StartSection{
+							Source: src(6, 6, "// ===BEGIN PRIVATE DOMAINS==="),
+							Name:   "PRIVATE DOMAINS",
+						},
+						Suggestion: "// ===END ICANN DOMAINS===",
+					},
+				},
+			},
+		},
+		{
+			name: "long_section_delimiters",
+			psl: dedent(`
+              // ====BEGIN ICANN DOMAINS====
+              // ====END ICANN DOMAINS===
+              // ===BEGIN PRIVATE DOMAINS=====
+              // ===END PRIVATE DOMAINS===
+            `),
+			want: File{
+				Blocks: []Block{
+					StartSection{
+						Source: src(1, 1, "// ====BEGIN ICANN DOMAINS===="),
+						Name:   "ICANN DOMAINS",
+					},
+					EndSection{
+						Source: src(2, 2, "// ====END ICANN DOMAINS==="),
+						Name:   "ICANN DOMAINS",
+					},
+					StartSection{
+						Source: src(3, 3, "// ===BEGIN PRIVATE DOMAINS====="),
+						Name:   "PRIVATE DOMAINS",
+					},
+					EndSection{
+						Source: src(4, 4, "// ===END PRIVATE DOMAINS==="),
+						Name:   "PRIVATE DOMAINS",
+					},
+				},
+				Errors: []error{
+					MalformedSectionDelimiterError{
+						Line:       src(1, 1, "// ====BEGIN ICANN DOMAINS===="),
+						Suggestion: "// ===BEGIN ICANN DOMAINS===",
+					},
+					MalformedSectionDelimiterError{
+						Line:       src(2, 2, "// ====END ICANN DOMAINS==="),
+						Suggestion: "// ===END ICANN DOMAINS===",
+					},
+					MalformedSectionDelimiterError{
+						Line:       src(3, 3, "// ===BEGIN PRIVATE DOMAINS====="),
+						Suggestion: "// ===BEGIN PRIVATE DOMAINS===",
+					},
+				},
+			},
+		},
+
 		{
 			name: "mismatched_sections",
 			psl: dedent(`
@@ -219,6 +357,40 @@ func TestParser(t *testing.T) {
 			},
 		},
 
+		{
+			name: "section_end_casing",
+			psl: dedent(`
+              // ===BEGIN ICANN DOMAINS===
+
+              // ===END icann domains===
+            `),
+			want: File{
+				Blocks: []Block{
+					StartSection{
+						Source: src(1, 1, "// ===BEGIN ICANN DOMAINS==="),
+						Name:   "ICANN DOMAINS",
+					},
+					EndSection{
+						Source: src(3, 3, "// ===END icann domains==="),
+						Name:   "ICANN DOMAINS",
+					},
+				},
+				Warnings: []error{
+					SectionEndCasingError{
+						Start: StartSection{
+							Source: src(1, 1, "// ===BEGIN ICANN DOMAINS==="),
+							Name:   "ICANN DOMAINS",
+						},
+						End: EndSection{
+							Source: src(3, 3, "// ===END icann domains==="),
+							Name:   "icann domains",
+						},
+						Suggestion: "// ===END ICANN DOMAINS===",
+					},
+				},
+			},
+		},
+
 		{
 			name: "unknown_section_header",
 			psl: dedent(`
@@ -592,6 +764,555 @@ func TestParser(t *testing.T) {
 				},
 			},
 		},
+
+		{
+			name: "numeric_suffixes",
+			psl: dedent(`
+              // Numbers Inc
+              192.168.1.1
+              10.0.0
+              *.127.0.0.1
+              [2001:db8::1]
+              0.example
+            `),
+			want: File{
+				Blocks: []Block{
+					Suffixes{
+						Source: src(1, 6, dedent(`
+                          // Numbers Inc
+                          192.168.1.1
+                          10.0.0
+                          *.127.0.0.1
+                          [2001:db8::1]
+                          0.example
+                        `)),
+						Header: []Source{
+							src(1, 1, "// Numbers Inc"),
+						},
+						Entries: []Source{
+							src(2, 2, "192.168.1.1"),
+							src(3, 3, "10.0.0"),
+							src(4, 4, "*.127.0.0.1"),
+							src(5, 5, "[2001:db8::1]"),
+							src(6, 6, "0.example"),
+						},
+						Entity: "Numbers Inc",
+					},
+				},
+				Errors: []error{
+					NumericSuffixError{Line: src(2, 2, "192.168.1.1")},
+					NumericSuffixError{Line: src(3, 3, "10.0.0")},
+					NumericSuffixError{Line: src(4, 4, "*.127.0.0.1")},
+					NumericSuffixError{Line: src(5, 5, "[2001:db8::1]")},
+				},
+			},
+		},
+
+		{
+			name: "multiple_suffixes_on_line",
+			psl:  "// Paste Corp\nfoo.example bar.example\nbaz.example\tqux.example\nok.example",
+			want: File{
+				Blocks: []Block{
+					Suffixes{
+						Source: src(1, 4, "// Paste Corp\nfoo.example bar.example\nbaz.example\tqux.example\nok.example"),
+						Header: []Source{
+							src(1, 1, "// Paste Corp"),
+						},
+						Entries: []Source{
+							src(2, 2, "foo.example bar.example"),
+							src(3, 3, "baz.example\tqux.example"),
+							src(4, 4, "ok.example"),
+						},
+						Entity: "Paste Corp",
+					},
+				},
+				Errors: []error{
+					MultipleSuffixesOnLineError{
+						Line:     src(2, 2, "foo.example bar.example"),
+						Suffixes: []string{"foo.example", "bar.example"},
+					},
+					MultipleSuffixesOnLineError{
+						Line:     src(3, 3, "baz.example\tqux.example"),
+						Suffixes: []string{"baz.example", "qux.example"},
+					},
+					TabCharacterError{
+						Line: src(3, 3, "baz.example\tqux.example"),
+					},
+				},
+			},
+		},
+
+		{
+			name: "non_contiguous_wildcard_group",
+			psl: dedent(`
+              // Wild Corp
+              *.wild.example
+              !a.wild.example
+              other.example
+              // Comments are fine.
+              !b.wild.example
+              *.tame.example
+              *.meek.example
+              !a.tame.example
+              !a.meek.example
+            `),
+			want: File{
+				Blocks: []Block{
+					Suffixes{
+						Source: src(1, 10, dedent(`
+                          // Wild Corp
+                          *.wild.example
+                          !a.wild.example
+                          other.example
+                          // Comments are fine.
+                          !b.wild.example
+                          *.tame.example
+                          *.meek.example
+                          !a.tame.example
+                          !a.meek.example
+                        `)),
+						Header: []Source{
+							src(1, 1, "// Wild Corp"),
+						},
+						Entries: []Source{
+							src(2, 2, "*.wild.example"),
+							src(3, 3, "!a.wild.example"),
+							src(4, 4, "other.example"),
+							src(6, 6, "!b.wild.example"),
+							src(7, 7, "*.tame.example"),
+							src(8, 8, "*.meek.example"),
+							src(9, 9, "!a.tame.example"),
+							src(10, 10, "!a.meek.example"),
+						},
+						InlineComments: []Source{
+							src(5, 5, "// Comments are fine."),
+						},
+						Entity: "Wild Corp",
+					},
+				},
+				Warnings: []error{
+					NonContiguousWildcardGroupWarning{
+						Wildcard:  src(2, 2, "*.wild.example"),
+						Separator: src(4, 4, "other.example"),
+					},
+				},
+			},
+		},
+
+		{
+			name: "invalid_exceptions",
+			psl: dedent(`
+              // Wild Corp
+              *.foo.example
+              !ok.foo.example
+              !foo.example
+              !deep.ok.foo.example
+              !unrelated.example
+            `),
+			want: File{
+				Blocks: []Block{
+					Suffixes{
+						Source: src(1, 6, dedent(`
+                          // Wild Corp
+                          *.foo.example
+                          !ok.foo.example
+                          !foo.example
+                          !deep.ok.foo.example
+                          !unrelated.example
+                        `)),
+						Header: []Source{
+							src(1, 1, "// Wild Corp"),
+						},
+						Entries: []Source{
+							src(2, 2, "*.foo.example"),
+							src(3, 3, "!ok.foo.example"),
+							src(4, 4, "!foo.example"),
+							src(5, 5, "!deep.ok.foo.example"),
+							src(6, 6, "!unrelated.example"),
+						},
+						Entity: "Wild Corp",
+					},
+				},
+				Errors: []error{
+					InvalidExceptionError{
+						Exception: src(4, 4, "!foo.example"),
+						Wildcard:  src(2, 2, "*.foo.example"),
+					},
+					InvalidExceptionError{
+						Exception: src(5, 5, "!deep.ok.foo.example"),
+						Wildcard:  src(2, 2, "*.foo.example"),
+					},
+				},
+			},
+		},
+
+		{
+			name: "url_and_email_on_one_line",
+			psl: dedent(`
+              // Example : https://example.com contact@example.com
+              example.com
+
+              // Other Corp : https://other.example/ Other Admin <admin@other.example>
+              other.example
+            `),
+			want: File{
+				Blocks: []Block{
+					Suffixes{
+						Source: src(1, 2, dedent(`
+                          // Example : https://example.com contact@example.com
+                          example.com
+                        `)),
+						Header: []Source{
+							src(1, 1, "// Example : https://example.com contact@example.com"),
+						},
+						Entries: []Source{
+							src(2, 2, "example.com"),
+						},
+						Entity:    "Example",
+						URL:       mustURL("https://example.com"),
+						Submitter: mustEmail("<contact@example.com>"),
+					},
+					Suffixes{
+						Source: src(4, 5, dedent(`
+                          // Other Corp : https://other.example/ Other Admin <admin@other.example>
+                          other.example
+                        `)),
+						Header: []Source{
+							src(4, 4, "// Other Corp : https://other.example/ Other Admin <admin@other.example>"),
+						},
+						Entries: []Source{
+							src(5, 5, "other.example"),
+						},
+						Entity:    "Other Corp",
+						URL:       mustURL("https://other.example/"),
+						Submitter: mustEmail("Other Admin <admin@other.example>"),
+					},
+				},
+				Warnings: []error{
+					CombinedURLEmailWarning{
+						Line: src(1, 1, "// Example : https://example.com contact@example.com"),
+					},
+					CombinedURLEmailWarning{
+						Line: src(4, 4, "// Other Corp : https://other.example/ Other Admin <admin@other.example>"),
+					},
+				},
+			},
+		},
+
+		{
+			name: "uppercase_suffixes",
+			psl: dedent(`
+              // Shouty Corp
+              Example.COM
+              *.WILD.example
+              quiet.example
+            `),
+			want: File{
+				Blocks: []Block{
+					Suffixes{
+						Source: src(1, 4, dedent(`
+                          // Shouty Corp
+                          Example.COM
+                          *.WILD.example
+                          quiet.example
+                        `)),
+						Header: []Source{
+							src(1, 1, "// Shouty Corp"),
+						},
+						Entries: []Source{
+							src(2, 2, "Example.COM"),
+							src(3, 3, "*.WILD.example"),
+							src(4, 4, "quiet.example"),
+						},
+						Entity: "Shouty Corp",
+					},
+				},
+				Warnings: []error{
+					UppercaseSuffixWarning{
+						Line:      src(2, 2, "Example.COM"),
+						Lowercase: "example.com",
+					},
+					UppercaseSuffixWarning{
+						Line:      src(3, 3, "*.WILD.example"),
+						Lowercase: "*.wild.example",
+					},
+				},
+			},
+		},
+
+		{
+			name: "leading_and_trailing_dots",
+			psl: dedent(`
+              // Dotty Corp
+              .example.com
+              example.org.
+              *.example.net.
+              !.www.example.net
+              .both.example.
+            `),
+			want: File{
+				Blocks: []Block{
+					Suffixes{
+						Source: src(1, 6, dedent(`
+                          // Dotty Corp
+                          .example.com
+                          example.org.
+                          *.example.net.
+                          !.www.example.net
+                          .both.example.
+                        `)),
+						Header: []Source{
+							src(1, 1, "// Dotty Corp"),
+						},
+						Entries: []Source{
+							src(2, 2, ".example.com"),
+							src(3, 3, "example.org."),
+							src(4, 4, "*.example.net."),
+							src(5, 5, "!.www.example.net"),
+							src(6, 6, ".both.example."),
+						},
+						Entity: "Dotty Corp",
+					},
+				},
+				Errors: []error{
+					LeadingDotError{
+						Line:       src(2, 2, ".example.com"),
+						Suggestion: "example.com",
+					},
+					TrailingDotError{
+						Line:       src(3, 3, "example.org."),
+						Suggestion: "example.org",
+					},
+					TrailingDotError{
+						Line:       src(4, 4, "*.example.net."),
+						Suggestion: "*.example.net",
+					},
+					LeadingDotError{
+						Line:       src(5, 5, "!.www.example.net"),
+						Suggestion: "!www.example.net",
+					},
+					LeadingDotError{
+						Line:       src(6, 6, ".both.example."),
+						Suggestion: "both.example",
+					},
+					TrailingDotError{
+						Line:       src(6, 6, ".both.example."),
+						Suggestion: "both.example",
+					},
+				},
+			},
+		},
+
+		{
+			name: "content_between_sections",
+			psl: dedent(`
+              // ===BEGIN ICANN DOMAINS===
+              // ===END ICANN DOMAINS===
+
+              // Stray comment.
+
+              // ===BEGIN PRIVATE DOMAINS===
+              // ===END PRIVATE DOMAINS===
+
+              // Trailing comments at the end are fine.
+            `),
+			want: File{
+				Blocks: []Block{
+					StartSection{
+						Source: src(1, 1, "// ===BEGIN ICANN DOMAINS==="),
+						Name:   "ICANN DOMAINS",
+					},
+					EndSection{
+						Source: src(2, 2, "// ===END ICANN DOMAINS==="),
+						Name:   "ICANN DOMAINS",
+					},
+					Comment{Source: src(4, 4, "// Stray comment.")},
+					StartSection{
+						Source: src(6, 6, "// ===BEGIN PRIVATE DOMAINS==="),
+						Name:   "PRIVATE DOMAINS",
+					},
+					EndSection{
+						Source: src(7, 7, "// ===END PRIVATE DOMAINS==="),
+						Name:   "PRIVATE DOMAINS",
+					},
+					Comment{Source: src(9, 9, "// Trailing comments at the end are fine.")},
+				},
+				Errors: []error{
+					ContentBetweenSectionsError{
+						Block: Comment{Source: src(4, 4, "// Stray comment.")},
+						After: EndSection{
+							Source: src(2, 2, "// ===END ICANN DOMAINS==="),
+							Name:   "ICANN DOMAINS",
+						},
+						Before: StartSection{
+							Source: src(6, 6, "// ===BEGIN PRIVATE DOMAINS==="),
+							Name:   "PRIVATE DOMAINS",
+						},
+					},
+				},
+			},
+		},
+
+		{
+			name: "section_name_whitespace",
+			psl: dedent(`
+              // ===BEGIN  ICANN  DOMAINS===
+
+              // ===END ICANN DOMAINS ===
+            `),
+			want: File{
+				Blocks: []Block{
+					StartSection{
+						Source: src(1, 1, "// ===BEGIN  ICANN  DOMAINS==="),
+						Name:   "ICANN DOMAINS",
+					},
+					EndSection{
+						Source: src(3, 3, "// ===END ICANN DOMAINS ==="),
+						Name:   "ICANN DOMAINS",
+					},
+				},
+				Warnings: []error{
+					SectionNameWhitespaceWarning{
+						Line:       src(1, 1, "// ===BEGIN  ICANN  DOMAINS==="),
+						Suggestion: "// ===BEGIN ICANN DOMAINS===",
+					},
+					SectionNameWhitespaceWarning{
+						Line:       src(3, 3, "// ===END ICANN DOMAINS ==="),
+						Suggestion: "// ===END ICANN DOMAINS===",
+					},
+				},
+			},
+		},
+
+		{
+			name: "suffixes_outside_sections",
+			psl: dedent(`
+              // Early Corp
+              early.example
+
+              // ===BEGIN ICANN DOMAINS===
+              // ===END ICANN DOMAINS===
+
+              // Late Corp
+              late.example
+            `),
+			want: File{
+				Blocks: []Block{
+					Suffixes{
+						Source:  src(1, 2, "// Early Corp\nearly.example"),
+						Header:  []Source{src(1, 1, "// Early Corp")},
+						Entries: []Source{src(2, 2, "early.example")},
+						Entity:  "Early Corp",
+					},
+					StartSection{
+						Source: src(4, 4, "// ===BEGIN ICANN DOMAINS==="),
+						Name:   "ICANN DOMAINS",
+					},
+					EndSection{
+						Source: src(5, 5, "// ===END ICANN DOMAINS==="),
+						Name:   "ICANN DOMAINS",
+					},
+					Suffixes{
+						Source:  src(7, 8, "// Late Corp\nlate.example"),
+						Header:  []Source{src(7, 7, "// Late Corp")},
+						Entries: []Source{src(8, 8, "late.example")},
+						Entity:  "Late Corp",
+					},
+				},
+				Errors: []error{
+					SuffixOutsideSectionError{
+						Suffixes: Suffixes{
+							Source:  src(1, 2, "// Early Corp\nearly.example"),
+							Header:  []Source{src(1, 1, "// Early Corp")},
+							Entries: []Source{src(2, 2, "early.example")},
+							Entity:  "Early Corp",
+						},
+					},
+					SuffixOutsideSectionError{
+						Suffixes: Suffixes{
+							Source:  src(7, 8, "// Late Corp\nlate.example"),
+							Header:  []Source{src(7, 7, "// Late Corp")},
+							Entries: []Source{src(8, 8, "late.example")},
+							Entity:  "Late Corp",
+						},
+					},
+				},
+			},
+		},
+
+		{
+			name: "missing_blank_lines_around_section_markers",
+			psl: dedent(`
+              // ===BEGIN ICANN DOMAINS===
+              // com
+              com
+              // ===END ICANN DOMAINS===
+              // ===BEGIN PRIVATE DOMAINS===
+              // Comment right after the start.
+
+              // ===END PRIVATE DOMAINS===
+            `),
+			want: File{
+				Blocks: []Block{
+					StartSection{
+						Source: src(1, 1, "// ===BEGIN ICANN DOMAINS==="),
+						Name:   "ICANN DOMAINS",
+					},
+					Suffixes{
+						Source:  src(2, 3, "// com\ncom"),
+						Header:  []Source{src(2, 2, "// com")},
+						Entries: []Source{src(3, 3, "com")},
+						Entity:  "com",
+					},
+					EndSection{
+						Source: src(4, 4, "// ===END ICANN DOMAINS==="),
+						Name:   "ICANN DOMAINS",
+					},
+					StartSection{
+						Source: src(5, 5, "// ===BEGIN PRIVATE DOMAINS==="),
+						Name:   "PRIVATE DOMAINS",
+					},
+					Comment{Source: src(6, 6, "// Comment right after the start.")},
+					EndSection{
+						Source: src(8, 8, "// ===END PRIVATE DOMAINS==="),
+						Name:   "PRIVATE DOMAINS",
+					},
+				},
+				Warnings: []error{
+					MissingBlankAfterSectionMarkerWarning{
+						Marker: StartSection{
+							Source: src(1, 1, "// ===BEGIN ICANN DOMAINS==="),
+							Name:   "ICANN DOMAINS",
+						},
+						Block: Suffixes{
+							Source:  src(2, 3, "// com\ncom"),
+							Header:  []Source{src(2, 2, "// com")},
+							Entries: []Source{src(3, 3, "com")},
+							Entity:  "com",
+						},
+					},
+					MissingBlankBeforeSectionMarkerWarning{
+						Marker: EndSection{
+							Source: src(4, 4, "// ===END ICANN DOMAINS==="),
+							Name:   "ICANN DOMAINS",
+						},
+						Block: Suffixes{
+							Source:  src(2, 3, "// com\ncom"),
+							Header:  []Source{src(2, 2, "// com")},
+							Entries: []Source{src(3, 3, "com")},
+							Entity:  "com",
+						},
+					},
+					MissingBlankAfterSectionMarkerWarning{
+						Marker: StartSection{
+							Source: src(5, 5, "// ===BEGIN PRIVATE DOMAINS==="),
+							Name:   "PRIVATE DOMAINS",
+						},
+						Block: Comment{Source: src(6, 6, "// Comment right after the start.")},
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -601,7 +1322,10 @@ func TestParser(t *testing.T) {
 				// use real exceptions if the test doesn't provide something else
 				exc = downgradeToWarning
 			}
-			got := parseWithExceptions(test.psl, exc)
+			got, err := parseWithExceptions(context.Background(), test.psl, ParseOptions{}, exc)
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
 			if diff := diff.Diff(&test.want, got); diff != "" {
 				t.Errorf("unexpected parse result (-want +got):\n%s", diff)
 			}
@@ -609,6 +1333,224 @@ func TestParser(t *testing.T) {
 	}
 }
 
+// TestParseMaxErrors checks that ParseOptions.MaxErrors stops parsing
+// once the error limit is exceeded.
+func TestParseMaxErrors(t *testing.T) {
+	psl := dedent(`
+      // ===ONE===
+
+      // ===TWO===
+
+      // ===THREE===
+
+      // ===FOUR===
+    `)
+
+	f := ParseWithOptions(psl, ParseOptions{MaxErrors: 2})
+	want := []error{
+		UnknownSectionMarker{Line: src(1, 1, "// ===ONE===")},
+		UnknownSectionMarker{Line: src(3, 3, "// ===TWO===")},
+		TooManyErrorsError{Max: 2},
+	}
+	if diff := diff.Diff(want, f.Errors); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+	if got := len(f.Blocks); got != 3 {
+		t.Errorf("parsed %d blocks, want parsing to stop after 3", got)
+	}
+
+	f = ParseWithOptions(psl, ParseOptions{})
+	if got := len(f.Errors); got != 4 {
+		t.Errorf("got %d errors with no limit, want 4", got)
+	}
+}
+
+// TestParseCommentPrefix checks that ParseOptions.CommentPrefix
+// changes the syntax of comments and section markers.
+func TestParseCommentPrefix(t *testing.T) {
+	psl := dedent(`
+      # ===BEGIN PRIVATE DOMAINS===
+
+      # Example Corp : https://example.com
+      # Submitted by Example <admin@example.com>
+      example.com
+
+      # ===END PRIVATE DOMAINS===
+    `)
+
+	f := ParseWithOptions(psl, ParseOptions{CommentPrefix: "#"})
+	want := &File{
+		Blocks: []Block{
+			StartSection{
+				Source: src(1, 1, "# ===BEGIN PRIVATE DOMAINS==="),
+				Name:   "PRIVATE DOMAINS",
+			},
+			Suffixes{
+				Source: src(3, 5, "# Example Corp : https://example.com\n# Submitted by Example <admin@example.com>\nexample.com"),
+				Header: []Source{
+					src(3, 3, "# Example Corp : https://example.com"),
+					src(4, 4, "# Submitted by Example <admin@example.com>"),
+				},
+				Entries:   []Source{src(5, 5, "example.com")},
+				Entity:    "Example Corp",
+				URL:       mustURL("https://example.com"),
+				Submitter: mustEmail("Example <admin@example.com>"),
+			},
+			EndSection{
+				Source: src(7, 7, "# ===END PRIVATE DOMAINS==="),
+				Name:   "PRIVATE DOMAINS",
+			},
+		},
+	}
+	if diff := diff.Diff(want, f); diff != "" {
+		t.Errorf("unexpected parse result (-want +got):\n%s", diff)
+	}
+
+	// With the default prefix, the same text is one big suffix block.
+	f = Parse(psl)
+	if len(f.Errors) == 0 {
+		t.Errorf("parsing with the default comment prefix found no errors")
+	}
+	// Suggested markers use the configured prefix.
+	f = ParseWithOptions(dedent(`
+      # ===BEGIN ICANN DOMAINS===
+      # ===BEGIN PRIVATE DOMAINS===
+      # ===END PRIVATE DOMAINS===
+    `), ParseOptions{CommentPrefix: "#"})
+	wantErrs := []error{
+		NestedSectionError{
+			Outer: StartSection{
+				Source: src(1, 1, "# ===BEGIN ICANN DOMAINS==="),
+				Name:   "ICANN DOMAINS",
+			},
+			Inner: StartSection{
+				Source: src(2, 2, "# ===BEGIN PRIVATE DOMAINS==="),
+				Name:   "PRIVATE DOMAINS",
+			},
+			Suggestion: "# ===END ICANN DOMAINS===",
+		},
+	}
+	if diff := diff.Diff(wantErrs, f.Errors); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseExtraValidators(t *testing.T) {
+	var calls int
+	countBlocks := func(f *File) []error {
+		calls++
+		if n := len(f.AllSuffixBlocks()); n != 5 {
+			return []error{fmt.Errorf("got %d suffix blocks, want 5", n)}
+		}
+		return nil
+	}
+	requireEntities := func(f *File) []error {
+		return f.RequireEntities([]string{"DuckCorp Inc", "Missing Corp"})
+	}
+
+	opts := ParseOptions{
+		ExtraValidators: []Validator{countBlocks, requireEntities},
+	}
+	f := ParseWithOptions(testFile, opts)
+	want := []error{
+		MissingRequiredEntityError{Name: "Missing Corp"},
+	}
+	if diff := diff.Diff(want, f.Errors); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+	if calls != 1 {
+		t.Errorf("countBlocks called %d times, want 1", calls)
+	}
+
+	// Like built-in validation, extra validators do not run on files
+	// that failed to parse.
+	calls = 0
+	f = ParseWithOptions("// ===BEGIN ICANN DOMAINS===\n", opts)
+	if len(f.Errors) == 0 {
+		t.Errorf("unclosed section parsed without errors")
+	}
+	if calls != 0 {
+		t.Errorf("countBlocks called %d times on a file with parse errors, want 0", calls)
+	}
+}
+
+func TestParseExtraValidatorsWarnings(t *testing.T) {
+	psl := dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // example : https://example.com
+      // Submitted by <admin@example.com>
+      example
+
+      // ===END ICANN DOMAINS===
+    `)
+	opts := ParseOptions{
+		ExtraValidators: []Validator{(*File).ValidateAllEmailsHaveDisplayNames},
+	}
+	f := ParseWithOptions(psl, opts)
+	if len(f.Errors) > 0 {
+		t.Errorf("unexpected errors: %v", f.Errors)
+	}
+	want := []error{
+		EmailMissingDisplayNameWarning{
+			Suffixes: f.AllSuffixBlocks()[0],
+			Address:  mustEmail("<admin@example.com>"),
+		},
+	}
+	if diff := diff.Diff(want, f.Warnings); diff != "" {
+		t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+	}
+	if !f.OK() {
+		t.Errorf("OK() = false for a file with only warnings")
+	}
+}
+
+func TestValid(t *testing.T) {
+	if !Valid([]byte(testFile)) {
+		t.Errorf("Valid(testFile) = false, want true")
+	}
+
+	// Uppercase suffixes only produce a warning.
+	warnOnly := []byte("// Example Corp\nEXAMPLE.com")
+	if f := Parse(string(warnOnly)); len(f.Warnings) == 0 {
+		t.Fatalf("test input has no warnings")
+	}
+	if !Valid(warnOnly) {
+		t.Errorf("Valid(file with warnings) = false, want true")
+	}
+
+	if Valid([]byte("// ===BEGIN ICANN DOMAINS===")) {
+		t.Errorf("Valid(unclosed section) = true, want false")
+	}
+}
+
+// TestParseContext checks that ParseContext stops parsing when its
+// context is canceled.
+func TestParseContext(t *testing.T) {
+	bs, err := os.ReadFile("../../../public_suffix_list.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := ParseContext(context.Background(), string(bs), ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseContext with live context failed: %v", err)
+	}
+	if len(f.Errors) > 0 {
+		t.Errorf("ParseContext with live context found errors: %v", f.Errors)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	f, err = ParseContext(ctx, string(bs), ParseOptions{})
+	if err != context.Canceled {
+		t.Errorf("ParseContext with canceled context returned error %v, want %v", err, context.Canceled)
+	}
+	if f != nil {
+		t.Errorf("ParseContext with canceled context returned non-nil File")
+	}
+}
+
 // mustURL returns the given string as a URL, or panics if not a URL.
 func mustURL(s string) *url.URL {
 	u, err := url.Parse(s)
@@ -766,6 +1708,17 @@ func TestExceptionsStillNecessary(t *testing.T) {
 			t.Errorf("missingEmail exception no longer necessary:\n%s", omitted)
 		}
 	})
+
+	forEachOmitted(missingDisplayName, func(omitted string, trimmed []string) {
+		old := missingDisplayName
+		defer func() { missingDisplayName = old }()
+		missingDisplayName = trimmed
+
+		f := Parse(string(bs))
+		if len(f.Errors) == 0 {
+			t.Errorf("missingDisplayName exception no longer necessary:\n%s", omitted)
+		}
+	})
 }
 
 func forEachOmitted(exceptions []string, fn func(string, []string)) {
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// urlCheckTimeout is the maximum time allowed for a single URL
+// reachability check.
+const urlCheckTimeout = 10 * time.Second
+
+// ValidateURLReachability checks that the URL of every suffix block
+// in f is reachable, by making an HTTP request to each one. Up to
+// concurrency requests are made in parallel, and each request is
+// given at most 10 seconds to complete.
+//
+// A URLNotReachableError is returned for every URL that fails to
+// respond, or responds with a non-2xx status. Errors are returned in
+// the order the blocks appear in f.
+//
+// This check requires network access and depends on the state of
+// third-party websites, so it is not run as part of Parse. It is
+// intended for pre-submission checks of new or changed blocks.
+func (f *File) ValidateURLReachability(ctx context.Context, concurrency int) []error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	blocks := f.AllSuffixBlocks()
+	results := make([]error, len(blocks))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, block := range blocks {
+		if block.URL == nil {
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i] = URLNotReachableError{Suffixes: block, URL: block.URL, Err: ctx.Err()}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, block Suffixes) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = checkURL(ctx, block)
+		}(i, block)
+	}
+	wg.Wait()
+
+	var ret []error
+	for _, err := range results {
+		if err != nil {
+			ret = append(ret, err)
+		}
+	}
+	return ret
+}
+
+// checkURL makes an HTTP request to block.URL and returns a
+// URLNotReachableError if the request fails.
+//
+// A HEAD request is tried first. Some servers don't support HEAD, so
+// if the response says the method is not allowed, the check is
+// retried with a GET request whose body is not read.
+func checkURL(ctx context.Context, block Suffixes) error {
+	status, err := fetchStatus(ctx, http.MethodHead, block.URL)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = fetchStatus(ctx, http.MethodGet, block.URL)
+	}
+	if err != nil || status < 200 || status > 299 {
+		return URLNotReachableError{
+			Suffixes:   block,
+			URL:        block.URL,
+			StatusCode: status,
+			Err:        err,
+		}
+	}
+	return nil
+}
+
+// fetchStatus makes an HTTP request with the given method to u, and
+// returns the response status code.
+func fetchStatus(ctx context.Context, method string, u *url.URL) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, urlCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
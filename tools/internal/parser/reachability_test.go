@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+func TestValidateURLReachability(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+		case "/get-only":
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := Parse(fmt.Sprintf(dedent(`
+      // OK Corp : %[1]s/ok
+      ok.example
+
+      // GET Only Corp : %[1]s/get-only
+      getonly.example
+
+      // Broken Corp : %[1]s/broken
+      broken.example
+
+      // Missing Corp : %[1]s/missing
+      missing.example
+
+      // No URL Corp
+      nourl.example
+    `), srv.URL))
+
+	type result struct {
+		Entity string
+		Status int
+	}
+	var got []result
+	for _, err := range f.ValidateURLReachability(context.Background(), 2) {
+		v, ok := err.(URLNotReachableError)
+		if !ok {
+			t.Errorf("unexpected error type %T: %v", err, err)
+			continue
+		}
+		if v.Err != nil {
+			t.Errorf("unexpected request error for %q: %v", v.URL, v.Err)
+		}
+		got = append(got, result{v.Suffixes.Entity, v.StatusCode})
+	}
+
+	want := []result{
+		{"Broken Corp", http.StatusInternalServerError},
+		{"Missing Corp", http.StatusNotFound},
+	}
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"html/template"
+	"io"
+)
+
+// DefaultHTMLTemplate is the html/template source used by RenderHTML
+// when no template is given. It renders a table of all suffix blocks,
+// with a search box that filters the table rows.
+const DefaultHTMLTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Public Suffix List</title>
+<style>
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+<input id="search" type="search" placeholder="Search" oninput="filterRows(this.value)">
+<table>
+<thead>
+<tr><th>Entity</th><th>URL</th><th>Submitter</th><th>Suffixes</th><th>Lines</th></tr>
+</thead>
+<tbody id="blocks">
+{{- range .AllSuffixBlocks}}
+<tr>
+<td>{{.Entity}}</td>
+<td>{{with .URL}}<a href="{{.}}">{{.}}</a>{{end}}</td>
+<td>{{with .Submitter}}{{if .Name}}{{.Name}} {{end}}&lt;{{.Address}}&gt;{{end}}</td>
+<td>{{range .Entries}}{{.Raw}}<br>{{end}}</td>
+<td>{{.LocationString}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+<script>
+function filterRows(query) {
+  query = query.toLowerCase();
+  for (const row of document.getElementById("blocks").rows) {
+    row.hidden = !row.textContent.toLowerCase().includes(query);
+  }
+}
+</script>
+</body>
+</html>
+`
+
+// defaultHTMLTemplate is the parsed form of DefaultHTMLTemplate.
+var defaultHTMLTemplate = template.Must(template.New("psl").Parse(DefaultHTMLTemplate))
+
+// RenderHTML executes tmpl with f as its data, and writes the output
+// to w. If tmpl is nil, DefaultHTMLTemplate is used.
+//
+// Templates have access to all exported fields and methods of File,
+// for example {{range .AllSuffixBlocks}} to iterate over all suffix
+// blocks, or {{range .SuffixBlocksInSection "PRIVATE DOMAINS"}} for
+// the blocks of one file section.
+func (f *File) RenderHTML(w io.Writer, tmpl *template.Template) error {
+	if tmpl == nil {
+		tmpl = defaultHTMLTemplate
+	}
+	return tmpl.Execute(w, f)
+}
@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+func TestRenderHTML(t *testing.T) {
+	f := Parse(testFile + "\n\n" + dedent(`
+      // <script> Corp : https://script.example
+      // Submitted by Script <s@script.example>
+      script.example
+    `))
+
+	var out strings.Builder
+	if err := f.RenderHTML(&out, nil); err != nil {
+		t.Fatalf("RenderHTML with default template failed: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"<td>DuckCorp Inc</td>",
+		`<a href="https://duck.example.org/">https://duck.example.org/</a>`,
+		"<td>Not A Duck &lt;duck@example.com&gt;</td>",
+		"<td>thing.example<br></td>",
+		"<td>&lt;script&gt; Corp</td>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("default template output does not contain %q", want)
+		}
+	}
+
+	tmpl := template.Must(template.New("custom").Parse(`{{range .SuffixBlocksInSection "PRIVATE DOMAINS"}}{{.Entity}};{{end}}`))
+	out.Reset()
+	if err := f.RenderHTML(&out, tmpl); err != nil {
+		t.Fatalf("RenderHTML with custom template failed: %v", err)
+	}
+	want := "AWS Thing;AWS Other Thing;DuckCorp Inc;No URL Corp;"
+	if diff := diff.Diff(want, out.String()); diff != "" {
+		t.Errorf("unexpected custom template output (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"strings"
+)
+
+// SearchIndex is a text search index over the suffix blocks of a
+// File, built by File.BuildSearchIndex.
+//
+// The index is a snapshot: it does not reflect edits made to the File
+// after it was built, and must be rebuilt after each edit.
+type SearchIndex struct {
+	blocks []Suffixes
+	// text[i] is the lowercased searchable text of blocks[i], one
+	// field per element.
+	text [][]string
+}
+
+// BuildSearchIndex returns a SearchIndex over all suffix blocks in f.
+// The searchable fields of each block are its entity name, URL,
+// submitter name and email address, and suffix entries.
+func (f *File) BuildSearchIndex() *SearchIndex {
+	ret := &SearchIndex{}
+	for _, block := range f.AllSuffixBlocks() {
+		var fields []string
+		add := func(s string) {
+			if s != "" {
+				fields = append(fields, strings.ToLower(s))
+			}
+		}
+
+		add(block.Entity)
+		if block.URL != nil {
+			add(block.URL.String())
+		}
+		if block.Submitter != nil {
+			add(block.Submitter.Name)
+			add(block.Submitter.Address)
+		}
+		for _, entry := range block.Entries {
+			add(entry.Raw)
+		}
+
+		ret.blocks = append(ret.blocks, block)
+		ret.text = append(ret.text, fields)
+	}
+	return ret
+}
+
+// Search returns the suffix blocks in which any searchable field
+// contains query, compared case-insensitively. Blocks are returned in
+// the order they appear in the file. An empty query matches all
+// blocks.
+func (idx *SearchIndex) Search(query string) []Suffixes {
+	var ret []Suffixes
+
+	query = strings.ToLower(query)
+	for i, fields := range idx.text {
+		for _, field := range fields {
+			if strings.Contains(field, query) {
+				ret = append(ret, idx.blocks[i])
+				break
+			}
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+func TestSearchIndex(t *testing.T) {
+	idx := Parse(testFile).BuildSearchIndex()
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// Entity name.
+		{"duckcorp", []string{"DuckCorp Inc"}},
+		// URL.
+		{"amazon.com/other", []string{"AWS Other Thing"}},
+		// Submitter name and email.
+		{"not a duck", []string{"DuckCorp Inc"}},
+		{"nobody@", []string{"No URL Corp"}},
+		// Suffix entries.
+		{"Other.EXAMPLE", []string{"AWS Other Thing"}},
+		{"nothing matches this", nil},
+	}
+
+	for _, test := range tests {
+		got := entities(idx.Search(test.query))
+		if diff := diff.Diff(test.want, got); diff != "" {
+			t.Errorf("Search(%q) wrong result (-want +got):\n%s", test.query, diff)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"strings"
+)
+
+// LineKind is the kind of a line of PSL source text, as classified by
+// Tokenize.
+type LineKind int
+
+const (
+	// BlankLine is an empty line, or a line of only whitespace.
+	BlankLine LineKind = iota
+	// CommentLine is a comment, other than a section marker.
+	CommentLine
+	// SectionStartLine is a marker that starts a file section, for
+	// example "// ===BEGIN ICANN DOMAINS===".
+	SectionStartLine
+	// SectionEndLine is a marker that ends a file section.
+	SectionEndLine
+	// SuffixLine is a suffix entry.
+	SuffixLine
+	// InvalidLine is a line that looks like a section marker, but is
+	// not a start or an end marker.
+	InvalidLine
+)
+
+func (k LineKind) String() string {
+	switch k {
+	case BlankLine:
+		return "blank"
+	case CommentLine:
+		return "comment"
+	case SectionStartLine:
+		return "section start"
+	case SectionEndLine:
+		return "section end"
+	case SuffixLine:
+		return "suffix"
+	case InvalidLine:
+		return "invalid"
+	default:
+		return "unknown"
+	}
+}
+
+// Line is a classified line of PSL source text.
+type Line struct {
+	Kind LineKind
+	// Text is the line, with leading and trailing whitespace
+	// removed.
+	Text string
+	// Number is the line number, starting at 1.
+	Number int
+}
+
+// Tokenize returns a function that classifies each line of bs in
+// turn, and passes it to yield until yield returns false. It is a
+// lower-level alternative to Parse, for consumers that build their
+// own structure from the lines of a PSL file.
+//
+// Lines are classified the same way as by Parse with the canonical
+// comment prefix, but Tokenize does not group lines into blocks and
+// does no validation. For example, a suffix entry that is not a valid
+// domain is still a SuffixLine. A leading byte order mark is ignored.
+//
+// The returned function has the same type as iter.Seq[Line], and can
+// be used as one.
+func Tokenize(bs []byte) func(yield func(Line) bool) {
+	return func(yield func(Line) bool) {
+		var p parser
+		src := strings.TrimPrefix(string(bs), "\ufeff")
+		for i, text := range strings.Split(src, "\n") {
+			text = strings.TrimSpace(text)
+			if !yield(Line{Kind: p.classifyLine(text), Text: text, Number: i + 1}) {
+				return
+			}
+		}
+	}
+}
+
+// classifyLine returns the kind of the whitespace-trimmed line text,
+// using the same comment and section marker prefixes as Parse.
+func (p *parser) classifyLine(text string) LineKind {
+	marker := p.markerPrefix()
+	switch {
+	case text == "":
+		return BlankLine
+	case strings.HasPrefix(text, marker+"BEGIN "):
+		return SectionStartLine
+	case strings.HasPrefix(text, marker+"END "):
+		return SectionEndLine
+	case strings.HasPrefix(text, marker):
+		return InvalidLine
+	case p.isComment(text):
+		return CommentLine
+	default:
+		return SuffixLine
+	}
+}
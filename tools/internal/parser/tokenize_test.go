@@ -0,0 +1,44 @@
+package parser
+
+import (
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+func TestTokenize(t *testing.T) {
+	psl := "\ufeff" + dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // example
+        example
+      // ===BOGUS===
+      // ===END ICANN DOMAINS===
+    `)
+
+	var got []Line
+	Tokenize([]byte(psl))(func(l Line) bool {
+		got = append(got, l)
+		return true
+	})
+	want := []Line{
+		{SectionStartLine, "// ===BEGIN ICANN DOMAINS===", 1},
+		{BlankLine, "", 2},
+		{CommentLine, "// example", 3},
+		{SuffixLine, "example", 4},
+		{InvalidLine, "// ===BOGUS===", 5},
+		{SectionEndLine, "// ===END ICANN DOMAINS===", 6},
+	}
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected lines (-want +got):\n%s", diff)
+	}
+
+	var n int
+	Tokenize([]byte(psl))(func(l Line) bool {
+		n++
+		return l.Kind != CommentLine
+	})
+	if n != 3 {
+		t.Errorf("Tokenize continued after yield returned false, got %d lines, want 3", n)
+	}
+}
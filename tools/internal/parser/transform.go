@@ -0,0 +1,346 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// NormalizeLabels rewrites all suffix entries in f into their
+// canonical ASCII form: internationalized labels are converted to
+// punycode A-labels (e.g. "公司" becomes "xn--55qx5d"), and ASCII
+// labels are lowercased. Wildcard and exception prefixes are
+// preserved.
+//
+// Both the entry Source and the enclosing Suffixes block Source are
+// updated, so that the blocks' source text reflects the rewritten
+// entries.
+//
+// Labels that cannot be converted are left unchanged, and reported
+// as LabelConversionError in the returned slice.
+func (f *File) NormalizeLabels() []error {
+	var errs []error
+
+	for i, block := range f.Blocks {
+		suffixes, ok := block.(Suffixes)
+		if !ok {
+			continue
+		}
+		suffixes = suffixes.Clone()
+
+		changed := false
+		for j, entry := range suffixes.Entries {
+			normalized, entryErrs := normalizeEntry(entry)
+			errs = append(errs, entryErrs...)
+			if normalized == entry.Raw {
+				continue
+			}
+			suffixes.Entries[j].Raw = normalized
+			suffixes.setRawLine(entry.StartLine, normalized)
+			changed = true
+		}
+
+		if changed {
+			f.Blocks[i] = suffixes
+		}
+	}
+
+	return errs
+}
+
+// normalizeEntry returns the canonical ASCII form of the suffix entry
+// in line, and any errors encountered while converting its labels.
+func normalizeEntry(line Source) (string, []error) {
+	var errs []error
+
+	prefix, domain := splitRulePrefix(line.Raw)
+
+	labels := strings.Split(domain, ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			labels[i] = strings.ToLower(label)
+			continue
+		}
+		alabel, err := idna.Lookup.ToASCII(label)
+		if err != nil {
+			errs = append(errs, LabelConversionError{
+				Line:  line,
+				Label: label,
+				Err:   err,
+			})
+			continue
+		}
+		labels[i] = alabel
+	}
+
+	return prefix + strings.Join(labels, "."), errs
+}
+
+// isASCII reports whether s consists only of ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// StripComments returns a copy of f with all comments removed, for
+// consumers that only need the suffix rules. Top-level comment blocks
+// are dropped, as are inline comments and all but the essential
+// header lines of suffix blocks. File section markers are kept, since
+// they distinguish ICANN suffixes from private suffixes.
+//
+// So that the result is still a valid PSL file, each suffix block
+// keeps the header line that names its entity and, in the private
+// domains section, the line that gives the submitter's contact
+// information.
+//
+// The remaining blocks are renumbered so that they are separated by a
+// single empty line, and the output of Marshal is compact. The
+// Entity, URL and Submitter of suffix blocks are kept, even if the
+// header lines they were extracted from are removed. The Errors and
+// Warnings of f are copied unchanged, and so may refer to line
+// numbers in f.
+func (f *File) StripComments() *File {
+	ret := &File{
+		Errors:   slices.Clone(f.Errors),
+		Warnings: slices.Clone(f.Warnings),
+	}
+
+	var curSection string
+	for _, block := range f.Blocks {
+		switch v := block.(type) {
+		case StartSection:
+			curSection = v.Name
+			ret.AddSectionStart(v.Name)
+		case EndSection:
+			curSection = ""
+			ret.AddSectionEnd(v.Name)
+		case Suffixes:
+			v = v.Clone()
+			v.Header = minimalHeader(v, curSection == "PRIVATE DOMAINS")
+			v.InlineComments = nil
+
+			start := ret.nextBlockLine()
+			var lines []string
+			for _, srcs := range [][]Source{v.Header, v.Entries} {
+				for i := range srcs {
+					srcs[i].StartLine = start + len(lines)
+					srcs[i].EndLine = start + len(lines)
+					lines = append(lines, srcs[i].Raw)
+				}
+			}
+			v.Source = Source{
+				StartLine: start,
+				EndLine:   start + len(lines) - 1,
+				Raw:       strings.Join(lines, "\n"),
+			}
+			ret.Blocks = append(ret.Blocks, v)
+		}
+	}
+
+	return ret
+}
+
+// minimalHeader returns the header lines of block that StripComments
+// keeps: the first line that contains the entity name and, if
+// withSubmitter is true, the first line with contact information.
+func minimalHeader(block Suffixes, withSubmitter bool) []Source {
+	var ret []Source
+	haveEntity, haveSubmitter := block.Entity == "", !withSubmitter
+	for _, line := range block.Header {
+		text := trimComment(line.Raw)
+		isEntity := !haveEntity && strings.Contains(text, block.Entity)
+		isSubmitter := !haveSubmitter && (block.Submitter != nil && strings.Contains(text, block.Submitter.Address) ||
+			strings.HasPrefix(strings.ToLower(text), submittedBy))
+		if !isEntity && !isSubmitter {
+			continue
+		}
+		ret = append(ret, line)
+		haveEntity = haveEntity || isEntity
+		haveSubmitter = haveSubmitter || isSubmitter
+	}
+	return ret
+}
+
+// SplitSuffixBlock returns a copy of f in which the suffix block for
+// entity is split in two. Entries of the block for which match
+// returns true move to a new block for newEntity, inserted directly
+// after the original block. The other entries, and any inline
+// comments, stay in the original block.
+//
+// The header of the new block is a copy of the original header, with
+// entity replaced by newEntity. The blocks of the result are
+// renumbered with RenumberLines. The Errors and Warnings of f are
+// copied unchanged, and so may refer to line numbers in f.
+//
+// Returns an error if f has no suffix block for entity, or if either
+// of the resulting blocks would have no entries. f itself is not
+// modified.
+func (f *File) SplitSuffixBlock(entity, newEntity string, match func(entry Source) bool) (*File, error) {
+	idx := slices.IndexFunc(f.Blocks, func(b Block) bool {
+		s, ok := b.(Suffixes)
+		return ok && s.Entity == entity
+	})
+	if idx < 0 {
+		return nil, fmt.Errorf("no suffix block for entity %q", entity)
+	}
+	block := f.Blocks[idx].(Suffixes)
+
+	var header, newHeader, keep, split []string
+	renamed := false
+	for _, line := range block.Header {
+		header = append(header, line.Raw)
+		if !renamed && strings.Contains(line.Raw, entity) {
+			newHeader = append(newHeader, strings.Replace(line.Raw, entity, newEntity, 1))
+			renamed = true
+		} else {
+			newHeader = append(newHeader, line.Raw)
+		}
+	}
+	var kept, moved int
+	for i, line := range strings.Split(block.Raw, "\n")[len(block.Header):] {
+		n := block.StartLine + len(block.Header) + i
+		switch {
+		case strings.HasPrefix(line, "//"):
+			keep = append(keep, line)
+		case match(Source{StartLine: n, EndLine: n, Raw: line}):
+			split = append(split, line)
+			moved++
+		default:
+			keep = append(keep, line)
+			kept++
+		}
+	}
+	if kept == 0 || moved == 0 {
+		return nil, fmt.Errorf("splitting the block for %q would leave a block with no suffixes", entity)
+	}
+
+	ret := f.clone()
+	ret.Blocks[idx] = suffixesFromLines(block.StartLine, append(header, keep...))
+	ret.Blocks = slices.Insert(ret.Blocks, idx+1, Block(suffixesFromLines(0, append(newHeader, split...))))
+	ret.RenumberLines()
+	return ret, nil
+}
+
+// DefaultEntityNameAcronyms are the words that NormalizeEntityNames
+// writes in a fixed form, rather than in title case.
+var DefaultEntityNameAcronyms = []string{
+	"AB", "AG", "AS", "AWS", "BV", "CDN", "DNS", "GmbH", "IT", "Inc", "LLC", "Ltd", "NIC", "SA", "SAS", "SL", "SRL", "UG",
+}
+
+// NormalizeEntityNames returns a copy of f in which the entity names
+// of private domain suffix blocks are written in title case: the
+// first letter of each space-separated word is uppercase, and the
+// rest lowercase. Words that match one of acronyms
+// case-insensitively (ignoring trailing punctuation) are written
+// exactly as given in acronyms instead, for example "LLC" or "GmbH".
+// DefaultEntityNameAcronyms is a reasonable default. Words containing
+// a dot, such as "NFT.Storage", and mixed-case words, such as "iDOT",
+// are left as written.
+//
+// The header line that holds each entity name is rewritten to match.
+// The number of entity names that changed is returned along with the
+// new File. f itself is not modified, so that the changes can be
+// reviewed before they are adopted.
+//
+// ICANN suffix blocks are conventionally named after their lowercase
+// TLD, and are left unchanged.
+func (f *File) NormalizeEntityNames(acronyms []string) (int, *File) {
+	ret := f.clone()
+
+	canonical := map[string]string{}
+	for _, acronym := range acronyms {
+		canonical[strings.ToLower(acronym)] = acronym
+	}
+
+	changed := 0
+	var curSection string
+	for i, block := range ret.Blocks {
+		switch v := block.(type) {
+		case StartSection:
+			curSection = v.Name
+			continue
+		case EndSection:
+			curSection = ""
+			continue
+		}
+		suffixes, ok := block.(Suffixes)
+		if !ok || curSection != "PRIVATE DOMAINS" || suffixes.Entity == "" {
+			continue
+		}
+		name := titleCaseEntity(suffixes.Entity, canonical)
+		if name == suffixes.Entity {
+			continue
+		}
+
+		suffixes = suffixes.Clone()
+		for j, line := range suffixes.Header {
+			if !strings.Contains(line.Raw, suffixes.Entity) {
+				continue
+			}
+			suffixes.Header[j].Raw = strings.Replace(line.Raw, suffixes.Entity, name, 1)
+			suffixes.setRawLine(line.StartLine, suffixes.Header[j].Raw)
+			suffixes.Entity = name
+			ret.Blocks[i] = suffixes
+			changed++
+			break
+		}
+	}
+
+	return changed, ret
+}
+
+// titleCaseEntity returns name in title case, with words found in
+// acronyms (keyed by their lowercase form) replaced by their
+// canonical form. Punctuation around a word, such as the parentheses
+// in "(AWS)", is kept as is. Words that look like domain names, such
+// as "is-a.dev", and words in deliberate mixed case, such as "iDOT",
+// are also kept as is.
+func titleCaseEntity(name string, acronyms map[string]string) string {
+	words := strings.Split(name, " ")
+	for i, word := range words {
+		bare := strings.TrimFunc(word, isPunctuation)
+		if bare == "" {
+			continue
+		}
+		start := strings.Index(word, bare)
+		prefix, suffix := word[:start], word[start+len(bare):]
+		if acronym, ok := acronyms[strings.ToLower(bare)]; ok {
+			words[i] = prefix + acronym + suffix
+			continue
+		}
+		if strings.Contains(bare, ".") || isMixedCase(bare) {
+			continue
+		}
+		runes := []rune(strings.ToLower(bare))
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = prefix + string(runes) + suffix
+	}
+	return strings.Join(words, " ")
+}
+
+// isMixedCase reports whether s contains both uppercase and
+// lowercase letters.
+func isMixedCase(s string) bool {
+	return strings.IndexFunc(s, unicode.IsUpper) >= 0 && strings.IndexFunc(s, unicode.IsLower) >= 0
+}
+
+// isPunctuation reports whether r is neither a letter nor a digit.
+func isPunctuation(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// setRawLine replaces the text of the given source line within
+// s.Raw. line is a line number in the original file, and must be
+// within s.
+func (s *Suffixes) setRawLine(line int, text string) {
+	lines := strings.Split(s.Raw, "\n")
+	lines[line-s.StartLine] = text
+	s.Raw = strings.Join(lines, "\n")
+}
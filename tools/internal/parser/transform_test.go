@@ -0,0 +1,262 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+)
+
+func TestNormalizeLabels(t *testing.T) {
+	f := Parse(dedent(`
+      // Example Corp
+      // Some other comment
+      公司.cn
+      Example.COM
+      *.食狮.公司.cn
+      !WWW.食狮.公司.cn
+      already.xn--55qx5d.cn
+    `))
+
+	errs := f.NormalizeLabels()
+	if len(errs) > 0 {
+		t.Fatalf("unexpected normalization errors: %v", errs)
+	}
+
+	want := []Block{
+		Suffixes{
+			Source: src(1, 7, dedent(`
+              // Example Corp
+              // Some other comment
+              xn--55qx5d.cn
+              example.com
+              *.xn--85x722f.xn--55qx5d.cn
+              !www.xn--85x722f.xn--55qx5d.cn
+              already.xn--55qx5d.cn
+            `)),
+			Header: []Source{
+				src(1, 1, "// Example Corp"),
+				src(2, 2, "// Some other comment"),
+			},
+			Entries: []Source{
+				src(3, 3, "xn--55qx5d.cn"),
+				src(4, 4, "example.com"),
+				src(5, 5, "*.xn--85x722f.xn--55qx5d.cn"),
+				src(6, 6, "!www.xn--85x722f.xn--55qx5d.cn"),
+				src(7, 7, "already.xn--55qx5d.cn"),
+			},
+			Entity: "Example Corp",
+		},
+	}
+	if diff := diff.Diff(want, f.Blocks); diff != "" {
+		t.Errorf("unexpected normalized blocks (-want +got):\n%s", diff)
+	}
+}
+
+func TestNormalizeLabelsErrors(t *testing.T) {
+	// U+00A0 is a non-breaking space, which is not allowed in
+	// domain labels.
+	f := Parse("// Example Corp\na\u00a0b.example")
+
+	errs := f.NormalizeLabels()
+	if len(errs) != 1 {
+		t.Fatalf("got %d errors, want 1: %v", len(errs), errs)
+	}
+	if _, ok := errs[0].(LabelConversionError); !ok {
+		t.Errorf("got error of type %T, want LabelConversionError", errs[0])
+	}
+}
+
+func TestNormalizeEntityNames(t *testing.T) {
+	orig := dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // com
+      com
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // ACME CORP, llc : https://acme.example
+      // Submitted by Acme <acme@example.com>
+      acme.example
+
+      // duckcorp gmbh
+      duck.example
+
+      // Proper Name Inc.
+      proper.example
+
+      // amazon web services (aws)
+      aws.example
+
+      // c.la
+      c.example
+
+      // is-a.dev : https://is-a.dev
+      is-a.example
+
+      // NFT.Storage
+      nft.example
+
+      // iDOT
+      idot.example
+
+      // ===END PRIVATE DOMAINS===
+    `) + "\n"
+	f := Parse(orig)
+
+	n, got := f.NormalizeEntityNames(DefaultEntityNameAcronyms)
+	if n != 3 {
+		t.Errorf("NormalizeEntityNames changed %d names, want 3", n)
+	}
+	want := strings.Replace(orig, "ACME CORP, llc", "Acme Corp, LLC", 1)
+	want = strings.Replace(want, "duckcorp gmbh", "Duckcorp GmbH", 1)
+	want = strings.Replace(want, "amazon web services (aws)", "Amazon Web Services (AWS)", 1)
+	if diff := diff.Diff(want, string(got.Marshal())); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+	if diff := diff.Diff(entities(Parse(want).AllSuffixBlocks()), entities(got.AllSuffixBlocks())); diff != "" {
+		t.Errorf("unexpected entity names (-want +got):\n%s", diff)
+	}
+
+	if diff := diff.Diff(orig, string(f.Marshal())); diff != "" {
+		t.Errorf("original file was modified (-want +got):\n%s", diff)
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	f := Parse(dedent(`
+      // This is the PSL.
+
+      // ===BEGIN ICANN DOMAINS===
+
+      // example
+      example
+      // Inline comment.
+      other.example
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Note: some private domains follow.
+
+      // Example Corp : https://example.com
+      // Submitted by Example <example@example.com>
+      example.com
+
+      // ===END PRIVATE DOMAINS===
+
+      // Trailing comment.
+    `))
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	got := f.StripComments()
+	want := dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // example
+      example
+      other.example
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Example Corp : https://example.com
+      // Submitted by Example <example@example.com>
+      example.com
+
+      // ===END PRIVATE DOMAINS===
+    `) + "\n"
+	if diff := diff.Diff(want, string(got.Marshal())); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+	if diff := diff.Diff([]string{"example", "Example Corp"}, entities(got.AllSuffixBlocks())); diff != "" {
+		t.Errorf("entity names not preserved (-want +got):\n%s", diff)
+	}
+
+	reparsed := Parse(string(got.Marshal()))
+	if len(reparsed.Errors) > 0 {
+		t.Errorf("reparsed output has errors: %v", reparsed.Errors)
+	}
+	if diff := diff.Diff(entries(got), entries(reparsed)); diff != "" {
+		t.Errorf("reparsed output has different entries (-want +got):\n%s", diff)
+	}
+}
+
+func TestSplitSuffixBlock(t *testing.T) {
+	f := Parse(dedent(`
+      // Example Corp : https://example.com
+      // Submitted by Example <example@example.com>
+      a.example.com
+      legacy-a.example.com
+      // Old stuff below.
+      legacy-b.example.com
+      b.example.com
+
+      // Other Corp : https://other.example
+      // Submitted by Other <other@other.example>
+      other.example
+    `))
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+	before := string(f.Marshal())
+
+	isLegacy := func(entry Source) bool {
+		return strings.HasPrefix(entry.Raw, "legacy-")
+	}
+	got, err := f.SplitSuffixBlock("Example Corp", "Example Corp Legacy", isLegacy)
+	if err != nil {
+		t.Fatalf("SplitSuffixBlock() failed: %v", err)
+	}
+
+	want := dedent(`
+      // Example Corp : https://example.com
+      // Submitted by Example <example@example.com>
+      a.example.com
+      // Old stuff below.
+      b.example.com
+
+      // Example Corp Legacy : https://example.com
+      // Submitted by Example <example@example.com>
+      legacy-a.example.com
+      legacy-b.example.com
+
+      // Other Corp : https://other.example
+      // Submitted by Other <other@other.example>
+      other.example
+    `) + "\n"
+	if diff := diff.Diff(want, string(got.Marshal())); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+	reparsed := Parse(string(got.Marshal()))
+	if diff := diff.Diff(reparsed.Blocks, got.Blocks); diff != "" {
+		t.Errorf("split blocks differ from parsed blocks (-parsed +split):\n%s", diff)
+	}
+	if after := string(f.Marshal()); after != before {
+		t.Errorf("SplitSuffixBlock() modified its input")
+	}
+
+	if _, err := f.SplitSuffixBlock("Missing Corp", "New Corp", isLegacy); err == nil {
+		t.Errorf("SplitSuffixBlock() of missing entity did not return an error")
+	}
+	if _, err := f.SplitSuffixBlock("Other Corp", "New Corp", isLegacy); err == nil {
+		t.Errorf("SplitSuffixBlock() with no matching entries did not return an error")
+	}
+	all := func(Source) bool { return true }
+	if _, err := f.SplitSuffixBlock("Example Corp", "New Corp", all); err == nil {
+		t.Errorf("SplitSuffixBlock() with all entries matching did not return an error")
+	}
+}
+
+// entries returns the suffix entries of f.
+func entries(f *File) []Source {
+	var ret []Source
+	for _, block := range f.AllSuffixBlocks() {
+		ret = append(ret, block.Entries...)
+	}
+	return ret
+}
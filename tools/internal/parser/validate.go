@@ -1,5 +1,19 @@
 package parser
 
+import (
+	"cmp"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+)
+
 // Validate runs validations on a parsed File.
 //
 // Validation only runs on a file that does not yet have any
@@ -10,8 +24,33 @@ func (p *parser) Validate() {
 		return
 	}
 
-	p.requireEntityNames()
-	p.requirePrivateDomainEmailContact()
+	validations := []func(){
+		p.requireEntityNames,
+		p.requirePrivateDomainEmailContact,
+		p.requirePrivateDomainEmailDisplayNames,
+		p.forbidMultipleSuffixesPerLine,
+		p.forbidNumericSuffixes,
+		p.forbidSuffixEdgeDots,
+		p.forbidContentBetweenSections,
+		p.requireExceptionsBelowWildcards,
+		p.forbidSuffixesOutsideSections,
+		p.forbidUnusualWhitespace,
+	}
+	for _, validate := range validations {
+		if p.ctx.Err() != nil {
+			return
+		}
+		validate()
+	}
+
+	for _, validate := range p.ExtraValidators {
+		if p.ctx.Err() != nil {
+			return
+		}
+		for _, err := range validate(&p.File) {
+			p.addError(err)
+		}
+	}
 }
 
 // requireEntityNames verifies that all Suffix blocks have some kind
@@ -37,3 +76,1643 @@ func (p *parser) requirePrivateDomainEmailContact() {
 		}
 	}
 }
+
+// requirePrivateDomainEmailDisplayNames verifies that all Suffix
+// blocks in the private section name a person alongside their email
+// contact.
+func (p *parser) requirePrivateDomainEmailDisplayNames() {
+	for _, err := range p.File.ValidateAllEmailsHaveDisplayNames() {
+		if _, ok := err.(EmailMissingDisplayNameError); ok {
+			p.addError(err)
+		}
+	}
+}
+
+// forbidContentBetweenSections verifies that no blocks appear between
+// the end of one file section and the start of the next, for example
+// between "===END ICANN DOMAINS===" and "===BEGIN PRIVATE DOMAINS===".
+func (p *parser) forbidContentBetweenSections() {
+	var (
+		prevEnd *EndSection
+		between []Block
+	)
+	for _, block := range p.Blocks {
+		switch v := block.(type) {
+		case EndSection:
+			prevEnd = &v
+			between = nil
+		case StartSection:
+			for _, b := range between {
+				p.addError(ContentBetweenSectionsError{
+					Block:  b,
+					After:  *prevEnd,
+					Before: v,
+				})
+			}
+			prevEnd = nil
+			between = nil
+		default:
+			if prevEnd != nil {
+				between = append(between, block)
+			}
+		}
+	}
+}
+
+// forbidSuffixesOutsideSections verifies that no suffix blocks appear
+// before the first file section or after the last one. Suffix blocks
+// between two sections are reported by forbidContentBetweenSections
+// instead.
+//
+// Files without any section markers are PSL fragments, such as test
+// inputs, and are not checked.
+func (p *parser) forbidSuffixesOutsideSections() {
+	var (
+		started   bool
+		inSection bool
+		outside   []Suffixes
+	)
+	report := func() {
+		for _, block := range outside {
+			p.addError(SuffixOutsideSectionError{
+				Suffixes: block,
+			})
+		}
+		outside = nil
+	}
+
+	for _, block := range p.Blocks {
+		switch v := block.(type) {
+		case StartSection:
+			if !started {
+				report()
+			}
+			outside = nil
+			started = true
+			inSection = true
+		case EndSection:
+			inSection = false
+		case Suffixes:
+			if !inSection {
+				outside = append(outside, v)
+			}
+		}
+	}
+	if started {
+		report()
+	}
+}
+
+// forbidMultipleSuffixesPerLine verifies that no suffix entry
+// contains spaces or tabs, e.g. "example.com example.net".
+func (p *parser) forbidMultipleSuffixesPerLine() {
+	for _, block := range p.AllSuffixBlocks() {
+		for _, entry := range block.Entries {
+			if fields := strings.Fields(entry.Raw); len(fields) > 1 {
+				p.addError(MultipleSuffixesOnLineError{
+					Line:     entry,
+					Suffixes: fields,
+				})
+			}
+		}
+	}
+}
+
+// requireExceptionsBelowWildcards verifies that exception rules that
+// relate to a wildcard are a direct subdomain of it. For the wildcard
+// "*.foo.example", "!x.foo.example" is valid, but "!foo.example" and
+// "!y.x.foo.example" have no effect on that wildcard.
+//
+// An exception that is a direct subdomain of some other wildcard is
+// valid. Exceptions that do not relate to any wildcard are not
+// reported.
+func (p *parser) requireExceptionsBelowWildcards() {
+	wildcards := map[string]Source{}
+	var exceptions []Source
+	for _, block := range p.AllSuffixBlocks() {
+		for _, entry := range block.Entries {
+			prefix, domain := splitRulePrefix(strings.ToLower(entry.Raw))
+			switch prefix {
+			case "*.":
+				if _, ok := wildcards[domain]; !ok {
+					wildcards[domain] = entry
+				}
+			case "!":
+				exceptions = append(exceptions, entry)
+			}
+		}
+	}
+
+	for _, exc := range exceptions {
+		_, domain := splitRulePrefix(strings.ToLower(exc.Raw))
+		if _, parent, ok := strings.Cut(domain, "."); ok {
+			if _, ok := wildcards[parent]; ok {
+				continue
+			}
+		}
+
+		// Find the closest wildcard that the exception is within or
+		// equal to, if any.
+		for d := domain; d != ""; {
+			if wildcard, ok := wildcards[d]; ok {
+				p.addError(InvalidExceptionError{
+					Exception: exc,
+					Wildcard:  wildcard,
+				})
+				break
+			}
+			_, d, _ = strings.Cut(d, ".")
+		}
+	}
+}
+
+// forbidUnusualWhitespace verifies that no line of the file contains
+// whitespace other than a plain space. See ValidateNoTabsInSource.
+func (p *parser) forbidUnusualWhitespace() {
+	for _, err := range p.File.ValidateNoTabsInSource() {
+		p.addError(err)
+	}
+}
+
+// forbidNumericSuffixes verifies that no suffix entry is an IP
+// address, or a domain made up entirely of numeric labels.
+func (p *parser) forbidNumericSuffixes() {
+	for _, block := range p.AllSuffixBlocks() {
+		for _, entry := range block.Entries {
+			if isNumericSuffix(entry.Raw) {
+				p.addError(NumericSuffixError{
+					Line: entry,
+				})
+			}
+		}
+	}
+}
+
+// forbidSuffixEdgeDots verifies that no suffix entry starts or ends
+// with a dot, e.g. ".example.com" or the fully qualified form
+// "example.com.".
+func (p *parser) forbidSuffixEdgeDots() {
+	for _, block := range p.AllSuffixBlocks() {
+		for _, entry := range block.Entries {
+			prefix, domain := splitRulePrefix(entry.Raw)
+			suggestion := prefix + strings.Trim(domain, ".")
+			if strings.HasPrefix(domain, ".") {
+				p.addError(LeadingDotError{
+					Line:       entry,
+					Suggestion: suggestion,
+				})
+			}
+			if strings.HasSuffix(domain, ".") {
+				p.addError(TrailingDotError{
+					Line:       entry,
+					Suggestion: suggestion,
+				})
+			}
+		}
+	}
+}
+
+// splitRulePrefix splits a suffix entry into its wildcard or
+// exception prefix ("*." or "!"), if any, and the domain the rule
+// applies to.
+func splitRulePrefix(entry string) (prefix, domain string) {
+	if rest, ok := strings.CutPrefix(entry, "!"); ok {
+		return "!", rest
+	}
+	if rest, ok := strings.CutPrefix(entry, "*."); ok {
+		return "*.", rest
+	}
+	return "", entry
+}
+
+// isNumericSuffix reports whether entry looks like an IPv4 or IPv6
+// address, or consists only of numeric labels (e.g. "10.0.0"),
+// ignoring any wildcard or exception prefix.
+func isNumericSuffix(entry string) bool {
+	_, entry = splitRulePrefix(entry)
+
+	if _, err := netip.ParseAddr(strings.Trim(entry, "[]")); err == nil {
+		return true
+	}
+
+	for _, label := range strings.Split(entry, ".") {
+		if label == "" {
+			return false
+		}
+		for _, r := range label {
+			if r < '0' || r > '9' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ValidatePrivateBeforeICANN checks that f contains both an "ICANN
+// DOMAINS" and a "PRIVATE DOMAINS" section, and that the ICANN
+// section comes first in the file.
+//
+// Returns MissingSectionError if either section is absent, or
+// SectionOutOfOrderError if the private section starts before the
+// ICANN section. Returns nil if the sections are correctly ordered.
+func (f *File) ValidatePrivateBeforeICANN() error {
+	var icann, private *StartSection
+	for _, block := range f.Blocks {
+		v, ok := block.(StartSection)
+		if !ok {
+			continue
+		}
+		switch {
+		case v.Name == "ICANN DOMAINS" && icann == nil:
+			icann = &v
+		case v.Name == "PRIVATE DOMAINS" && private == nil:
+			private = &v
+		}
+	}
+
+	if icann == nil {
+		return MissingSectionError{Name: "ICANN DOMAINS"}
+	}
+	if private == nil {
+		return MissingSectionError{Name: "PRIVATE DOMAINS"}
+	}
+	if private.StartLine < icann.StartLine {
+		return SectionOutOfOrderError{
+			ICANN:   *icann,
+			Private: *private,
+		}
+	}
+	return nil
+}
+
+// ValidateOrdering checks that the suffix blocks of the private
+// domains section are sorted by entity name, as the PSL's
+// contribution guidelines require. Entity names are compared
+// case-insensitively, ignoring differences in whitespace.
+//
+// Returns SuffixBlocksInWrongPlace listing the blocks that must move
+// to sort the section, along with an edit script that sorts it, or
+// nil if the section is sorted. The listed blocks are a smallest such
+// set: all other blocks are already in order relative to each other.
+// Blocks without an entity name are ignored.
+//
+// Many existing blocks predate the sorting requirement, so this
+// check is not run as part of Parse. It is intended for checking
+// edits, by comparing the result before and after the edit.
+func (f *File) ValidateOrdering() error {
+	var (
+		blocks []Suffixes
+		keys   []string
+	)
+	for _, block := range f.SuffixBlocksInSection("PRIVATE DOMAINS") {
+		if block.Entity == "" {
+			continue
+		}
+		blocks = append(blocks, block)
+		keys = append(keys, entitySortKey(block.Entity))
+	}
+
+	inOrder := longestSortedSubsequence(keys)
+	var (
+		misplaced  []int
+		sorted     []int
+		sortedKeys []string
+	)
+	for i := range blocks {
+		if inOrder[i] {
+			sorted = append(sorted, i)
+			sortedKeys = append(sortedKeys, keys[i])
+		} else {
+			misplaced = append(misplaced, i)
+		}
+	}
+	if len(misplaced) == 0 {
+		return nil
+	}
+
+	// Moves are applied in order, so each one is placed relative to
+	// the sorted blocks plus the blocks already moved by earlier
+	// steps of the script.
+	var ret SuffixBlocksInWrongPlace
+	for _, i := range misplaced {
+		move := MoveSuffixBlock{Name: blocks[i].Entity}
+		// Insert after the last placed block that sorts before or
+		// equal to this one.
+		n, _ := slices.BinarySearchFunc(sortedKeys, keys[i], func(k, key string) int {
+			if k <= key {
+				return -1
+			}
+			return 1
+		})
+		if n > 0 {
+			move.InsertAfter = blocks[sorted[n-1]].Entity
+		}
+		sorted = slices.Insert(sorted, n, i)
+		sortedKeys = slices.Insert(sortedKeys, n, keys[i])
+		ret.Blocks = append(ret.Blocks, blocks[i])
+		ret.EditScript = append(ret.EditScript, move)
+	}
+	return ret
+}
+
+// entitySortKey returns the key by which ValidateOrdering sorts the
+// entity name entity.
+func entitySortKey(entity string) string {
+	return strings.ToLower(strings.Join(strings.Fields(entity), " "))
+}
+
+// longestSortedSubsequence returns the indexes of keys that belong to
+// a longest sorted (non-decreasing) subsequence of keys. Elements not
+// in the subsequence are the fewest that must move to sort keys.
+func longestSortedSubsequence[K cmp.Ordered](keys []K) map[int]bool {
+	var (
+		// tails[n] is the index of the smallest key that ends a
+		// sorted subsequence of length n+1.
+		tails []int
+		// prev[i] is the index of the element before keys[i] in the
+		// longest sorted subsequence ending at keys[i], or -1.
+		prev = make([]int, len(keys))
+	)
+	for i, key := range keys {
+		n, _ := slices.BinarySearchFunc(tails, key, func(t int, key K) int {
+			if keys[t] <= key {
+				return -1
+			}
+			return 1
+		})
+		prev[i] = -1
+		if n > 0 {
+			prev[i] = tails[n-1]
+		}
+		if n == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[n] = i
+		}
+	}
+
+	ret := map[int]bool{}
+	if len(tails) == 0 {
+		return ret
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		ret[i] = true
+	}
+	return ret
+}
+
+// DefaultMinEntityNameLength is the suggested minimum entity name
+// length for DetectTooShortEntities.
+const DefaultMinEntityNameLength = 3
+
+// DetectTooShortEntities reports private domain suffix blocks whose
+// entity name looks like a placeholder or test submission.
+//
+// Names shorter than minLength characters produce
+// SuspiciouslyShortEntityWarning. Names that contain no letters at
+// all (e.g. "123" or "...") produce NonAlphanumericEntityWarning.
+//
+// Only the private domains section is checked, since ICANN suffix
+// blocks are conventionally named after their TLD, which is often
+// only two characters long.
+//
+// These checks are heuristics, and are not run as part of Parse.
+func (f *File) DetectTooShortEntities(minLength int) []error {
+	var ret []error
+	for _, block := range f.SuffixBlocksInSection("PRIVATE DOMAINS") {
+		if block.Entity == "" {
+			// Reported by MissingEntityName during validation.
+			continue
+		}
+		if utf8.RuneCountInString(block.Entity) < minLength {
+			ret = append(ret, SuspiciouslyShortEntityWarning{
+				Suffixes: block,
+			})
+		}
+		if !strings.ContainsFunc(block.Entity, unicode.IsLetter) {
+			ret = append(ret, NonAlphanumericEntityWarning{
+				Suffixes: block,
+			})
+		}
+	}
+	return ret
+}
+
+// DetectURLDomainMismatches reports private domain suffix blocks
+// where none of the block's suffixes share a registrable domain with
+// the host of the block's URL. Such a mismatch can indicate a header
+// copied from another block by mistake.
+//
+// Registrable domains are computed using only the ICANN section of
+// f, since every private suffix is by definition a public suffix.
+// Blocks without a URL are not checked.
+//
+// There are legitimate reasons for a mismatch, for example a company
+// whose website is on a different domain from its suffixes, so this
+// check produces URLDomainMismatchWarning and is not run as part of
+// Parse.
+func (f *File) DetectURLDomainMismatches() []error {
+	var ret []error
+
+	icann := newRuleSet(f.SuffixBlocksInSection("ICANN DOMAINS"))
+	for _, block := range f.SuffixBlocksInSection("PRIVATE DOMAINS") {
+		if block.URL == nil {
+			continue
+		}
+		urlDomain, err := icann.registrableDomain(block.URL.Hostname())
+		if err != nil {
+			continue
+		}
+
+		matched := false
+		for _, entry := range block.Entries {
+			_, domain := splitRulePrefix(entry.Raw)
+			if d, err := icann.registrableDomain(domain); err == nil && d == urlDomain {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			ret = append(ret, URLDomainMismatchWarning{
+				Suffixes:  block,
+				URLDomain: urlDomain,
+			})
+		}
+	}
+
+	return ret
+}
+
+// DetectEmailDomainMismatches reports private domain suffix blocks
+// whose contact email is not on the same registrable domain as any of
+// the block's suffixes, or as the block's URL. Such a mismatch can
+// indicate a stale contact, or a submission by a third party.
+//
+// Registrable domains are computed as in DetectURLDomainMismatches.
+// Blocks without a contact email are not checked.
+//
+// Agencies and hosting providers legitimately submit suffixes on
+// behalf of others, so this check produces EmailDomainMismatchWarning
+// and is not run as part of Parse.
+func (f *File) DetectEmailDomainMismatches() []error {
+	var ret []error
+
+	icann := newRuleSet(f.SuffixBlocksInSection("ICANN DOMAINS"))
+	for _, block := range f.SuffixBlocksInSection("PRIVATE DOMAINS") {
+		if block.Submitter == nil {
+			continue
+		}
+		_, host, ok := strings.Cut(block.Submitter.Address, "@")
+		if !ok {
+			continue
+		}
+		emailDomain, err := icann.registrableDomain(host)
+		if err != nil {
+			continue
+		}
+
+		var domains []string
+		if block.URL != nil {
+			domains = append(domains, block.URL.Hostname())
+		}
+		for _, entry := range block.Entries {
+			_, domain := splitRulePrefix(entry.Raw)
+			domains = append(domains, domain)
+		}
+		matched := false
+		for _, domain := range domains {
+			if d, err := icann.registrableDomain(domain); err == nil && d == emailDomain {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		line := block.Source
+		for _, h := range block.Header {
+			if strings.Contains(h.Raw, block.Submitter.Address) {
+				line = h
+				break
+			}
+		}
+		ret = append(ret, EmailDomainMismatchWarning{
+			Suffixes:    block,
+			Line:        line,
+			EmailDomain: emailDomain,
+		})
+	}
+
+	return ret
+}
+
+// maxURLPathSegments is the maximum number of path segments that
+// ValidateNoSubpathURLs allows in a URL.
+const maxURLPathSegments = 2
+
+// ValidateNoSubpathURLs reports suffix blocks whose URL has more than
+// two path segments, for example
+// "https://example.com/internal/psl/entry". Deep links tend to break
+// sooner than top-level pages such as "https://example.com/about".
+//
+// This check produces DeepPathURLWarning and is not run as part of
+// Parse.
+func (f *File) ValidateNoSubpathURLs() []error {
+	var ret []error
+	for _, block := range f.AllSuffixBlocks() {
+		if block.URL == nil {
+			continue
+		}
+		path := strings.Trim(block.URL.Path, "/")
+		if path == "" {
+			continue
+		}
+		if strings.Count(path, "/")+1 > maxURLPathSegments {
+			ret = append(ret, DeepPathURLWarning{
+				Suffixes: block,
+				URL:      block.URL,
+			})
+		}
+	}
+	return ret
+}
+
+// DetectSharedURLs reports groups of private domain suffix blocks
+// that have the same URL, but different entity names. Each group
+// produces one SharedURLDifferentEntityWarning listing all blocks
+// that share the URL.
+//
+// URLs are compared after normalization: the scheme, a leading "www."
+// in the host and a trailing "/" in the path are ignored, and the
+// host is compared case-insensitively.
+//
+// Unrelated entities can legitimately share a URL, for example
+// several products documented on one page, so this check is not run
+// as part of Parse.
+func (f *File) DetectSharedURLs() []error {
+	var ret []error
+
+	byURL := map[string][]Suffixes{}
+	var order []string
+	for _, block := range f.SuffixBlocksInSection("PRIVATE DOMAINS") {
+		if block.URL == nil {
+			continue
+		}
+		u := normalizeURL(block.URL)
+		if _, ok := byURL[u]; !ok {
+			order = append(order, u)
+		}
+		byURL[u] = append(byURL[u], block)
+	}
+
+	for _, u := range order {
+		blocks := byURL[u]
+		for _, block := range blocks[1:] {
+			if block.Entity != blocks[0].Entity {
+				ret = append(ret, SharedURLDifferentEntityWarning{
+					URL:    u,
+					Blocks: blocks,
+				})
+				break
+			}
+		}
+	}
+
+	return ret
+}
+
+// normalizeURL returns a string form of u suitable for comparing
+// whether two URLs refer to the same page.
+func normalizeURL(u *url.URL) string {
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	path := strings.TrimSuffix(u.Path, "/")
+	ret := host + path
+	if u.RawQuery != "" {
+		ret += "?" + u.RawQuery
+	}
+	return ret
+}
+
+// DetectNearDuplicateEntities reports private domain suffix blocks
+// whose entity names differ only in case or whitespace, for example
+// "GitHub" and "Github", or "Duck Corp" and "DuckCorp". Each such
+// block produces a DuplicateEntityWarning that refers back to the
+// first block with a differently written name.
+//
+// Blocks whose entity names are identical are not reported.
+func (f *File) DetectNearDuplicateEntities() []error {
+	var ret []error
+
+	first := map[string]Suffixes{}
+	for _, block := range f.SuffixBlocksInSection("PRIVATE DOMAINS") {
+		if block.Entity == "" {
+			continue
+		}
+		key := strings.ToLower(strings.Join(strings.Fields(block.Entity), ""))
+		prev, ok := first[key]
+		if !ok {
+			first[key] = block
+			continue
+		}
+		if prev.Entity != block.Entity {
+			ret = append(ret, DuplicateEntityWarning{
+				First:  prev,
+				Second: block,
+			})
+		}
+	}
+
+	return ret
+}
+
+// DefaultRoleEmailPrefixes are the suggested role address local
+// parts for ValidateEmailNotRole.
+var DefaultRoleEmailPrefixes = []string{
+	"abuse",
+	"admin",
+	"contact",
+	"hostmaster",
+	"info",
+	"no-reply",
+	"noreply",
+	"postmaster",
+	"support",
+	"webmaster",
+}
+
+// ValidateEmailNotRole reports suffix blocks whose submitter email
+// address is a role address, rather than the address of a person. An
+// address is a role address if its local part (before the "@") is
+// one of rolePrefixes, optionally followed by one of "+-._" and more
+// text, compared case-insensitively. For example, with the prefix
+// "support", both "support@example.com" and
+// "support-psl@example.com" are role addresses.
+//
+// Some organizations use monitored role addresses legitimately, so
+// this check produces RoleEmailAddressWarning and is not run as part
+// of Parse.
+func (f *File) ValidateEmailNotRole(rolePrefixes []string) []error {
+	var ret []error
+	for _, block := range f.AllSuffixBlocks() {
+		if block.Submitter == nil {
+			continue
+		}
+		local, _, _ := strings.Cut(block.Submitter.Address, "@")
+		if isRoleAddress(strings.ToLower(local), rolePrefixes) {
+			ret = append(ret, RoleEmailAddressWarning{
+				Suffixes: block,
+				Address:  block.Submitter,
+			})
+		}
+	}
+	return ret
+}
+
+// ValidateAllEmailsHaveDisplayNames reports suffix blocks whose
+// submitter has an email address but no name, for example
+// "Submitted by <admin@example.com>". PSL guidelines require contact
+// information to name a person.
+//
+// Blocks in the private domains section produce
+// EmailMissingDisplayNameError. Other blocks, whose contacts are
+// typically registry role accounts, produce
+// EmailMissingDisplayNameWarning.
+//
+// Parse reports the errors for private domains blocks, except for
+// legacy blocks that predate this guideline. The warnings are not
+// reported by Parse.
+func (f *File) ValidateAllEmailsHaveDisplayNames() []error {
+	var ret []error
+	f.forEachSuffixBlock(func(section string, block Suffixes) {
+		if block.Submitter == nil || strings.TrimSpace(block.Submitter.Name) != "" {
+			return
+		}
+		if section == "PRIVATE DOMAINS" {
+			ret = append(ret, EmailMissingDisplayNameError{
+				Suffixes: block,
+				Address:  block.Submitter,
+			})
+		} else {
+			ret = append(ret, EmailMissingDisplayNameWarning{
+				Suffixes: block,
+				Address:  block.Submitter,
+			})
+		}
+	})
+	return ret
+}
+
+// isRoleAddress reports whether the email local part local is one of
+// rolePrefixes, possibly followed by a separator and more text.
+func isRoleAddress(local string, rolePrefixes []string) bool {
+	for _, prefix := range rolePrefixes {
+		rest, ok := strings.CutPrefix(local, strings.ToLower(prefix))
+		if ok && (rest == "" || strings.ContainsRune("+-._", rune(rest[0]))) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateIDNARegistration reports internationalized labels of
+// suffix entries that IDNA2008 does not allow to be registered, using
+// the strict registration profile of golang.org/x/net/idna. Both
+// Unicode labels and punycode "xn--" labels are checked, the latter
+// after decoding. Each invalid label produces a
+// DisallowedIDNACharacterError.
+//
+// These rules are stricter than those applied by NormalizeLabels,
+// and some registries' legacy names do not follow them, so this check
+// is opt-in and not run as part of Parse.
+func (f *File) ValidateIDNARegistration() []error {
+	var ret []error
+	for _, block := range f.AllSuffixBlocks() {
+		for _, entry := range block.Entries {
+			_, domain := splitRulePrefix(entry.Raw)
+			for _, label := range strings.Split(domain, ".") {
+				if err := checkIDNARegistration(entry, label); err != nil {
+					ret = append(ret, err)
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// checkIDNARegistration checks one label of the suffix entry in line
+// for ValidateIDNARegistration.
+func checkIDNARegistration(line Source, label string) error {
+	ulabel := label
+	if strings.HasPrefix(strings.ToLower(label), "xn--") {
+		u, err := idna.Punycode.ToUnicode(label)
+		if err != nil {
+			return DisallowedIDNACharacterError{Line: line, Label: label, Err: err}
+		}
+		ulabel = u
+	} else if isASCII(label) {
+		return nil
+	}
+
+	_, err := idna.Registration.ToASCII(ulabel)
+	if err == nil {
+		return nil
+	}
+	ret := DisallowedIDNACharacterError{Line: line, Label: label, Err: err}
+	// The idna package does not say which code point it rejected,
+	// so guess by checking each one on its own.
+	for _, r := range ulabel {
+		if _, err := idna.Registration.ToASCII(string(r)); err != nil {
+			ret.CodePoint = r
+			break
+		}
+	}
+	return ret
+}
+
+// DetectNonASCIIEntities returns the suffix blocks of f whose entity
+// name contains non-ASCII characters. Such names are valid, but may
+// need special care from tools that process the PSL.
+func (f *File) DetectNonASCIIEntities() []Suffixes {
+	var ret []Suffixes
+	for _, block := range f.AllSuffixBlocks() {
+		if !isASCII(block.Entity) {
+			ret = append(ret, block)
+		}
+	}
+	return ret
+}
+
+// ValidateNonASCIIEntities reports suffix blocks whose non-ASCII
+// entity name is not in Unicode Normalization Form C (NFC). Names
+// that look identical but are encoded differently compare unequal,
+// which breaks searching and sorting by entity name.
+func (f *File) ValidateNonASCIIEntities() []error {
+	var ret []error
+	for _, block := range f.DetectNonASCIIEntities() {
+		if !norm.NFC.IsNormalString(block.Entity) {
+			ret = append(ret, NonNormalizedEntityNameError{
+				Suffixes: block,
+			})
+		}
+	}
+	return ret
+}
+
+// DetectMalformedSectionMarkers reports top-level comment lines that
+// look like attempts at section markers (e.g. "===BEGIN ICANN
+// DOMAINS==="), but are malformed and so were parsed as plain
+// comments. Typical mistakes include too few "=" characters, a
+// missing space after the "//", or extra spaces around the marker
+// keyword. Markers with too many "=" characters are reported by Parse
+// as MalformedSectionDelimiterError.
+//
+// Each MalformedSectionMarker includes the suggested correct form of
+// the marker.
+//
+// Some of these lines also produce UnknownSectionMarker errors during
+// parsing. Others, such as markers missing the space after "//", are
+// silently accepted as comments by Parse and are only found by this
+// check.
+func (f *File) DetectMalformedSectionMarkers() []error {
+	var ret []error
+
+	for _, block := range f.Blocks {
+		comment, ok := block.(Comment)
+		if !ok {
+			continue
+		}
+		for i, line := range strings.Split(comment.Raw, "\n") {
+			suggestion, ok := suggestSectionMarker(line)
+			if !ok {
+				continue
+			}
+			ret = append(ret, MalformedSectionMarker{
+				Line: Source{
+					StartLine: comment.StartLine + i,
+					EndLine:   comment.StartLine + i,
+					Raw:       line,
+				},
+				Suggestion: suggestion,
+			})
+		}
+	}
+
+	return ret
+}
+
+// suggestSectionMarker reports whether line looks like an attempt at
+// a section marker and, if so, returns the correctly formatted
+// marker.
+func suggestSectionMarker(line string) (suggestion string, ok bool) {
+	text := strings.TrimSpace(strings.TrimPrefix(line, "//"))
+	if !strings.HasPrefix(text, "=") {
+		return "", false
+	}
+	text = strings.TrimSpace(strings.Trim(text, "="))
+
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return "", false
+	}
+	kind := strings.ToUpper(fields[0])
+	if kind != "BEGIN" && kind != "END" {
+		return "", false
+	}
+
+	suggestion = sectionMarker + kind + " " + strings.Join(fields[1:], " ") + "==="
+	if suggestion == line {
+		// Well-formed marker, nothing to suggest.
+		return "", false
+	}
+	return suggestion, true
+}
+
+// ValidateNoAllCapsEntities reports private domain suffix blocks whose
+// entity name is written entirely in uppercase (AllCapsEntityNameWarning)
+// or entirely in lowercase (AllLowerCaseEntityNameWarning). Proper
+// names are conventionally written in title case.
+//
+// Only letters that have a case are considered, so names in scripts
+// without letter case are never reported. Only the private domains
+// section is checked, since ICANN suffix blocks are conventionally
+// named after their lowercase TLD.
+//
+// These are style lints, and are not run as part of Parse.
+func (f *File) ValidateNoAllCapsEntities() []error {
+	var ret []error
+	for _, block := range f.SuffixBlocksInSection("PRIVATE DOMAINS") {
+		upper, lower := 0, 0
+		for _, r := range block.Entity {
+			switch {
+			case unicode.IsUpper(r):
+				upper++
+			case unicode.IsLower(r):
+				lower++
+			}
+		}
+		switch {
+		case upper > 0 && lower == 0:
+			ret = append(ret, AllCapsEntityNameWarning{
+				Suffixes: block,
+			})
+		case lower > 0 && upper == 0:
+			ret = append(ret, AllLowerCaseEntityNameWarning{
+				Suffixes: block,
+			})
+		}
+	}
+	return ret
+}
+
+// DetectSubdomainConflicts reports suffix entries that are a proper
+// subdomain of another entry in f, for example "foo.example.com" when
+// "example.com" is also listed. Each such entry produces one
+// SubdomainConflictWarning, naming the closest listed parent.
+//
+// Only plain entries are compared. Wildcard and exception rules are
+// ignored, since by design they describe the subdomains of another
+// domain.
+//
+// Nested suffixes are allowed by the PSL format and are common in the
+// ICANN section, so this check produces warnings and is not run as
+// part of Parse.
+func (f *File) DetectSubdomainConflicts() []error {
+	var ret []error
+
+	plain := map[string]Source{}
+	var order []Source
+	for _, block := range f.AllSuffixBlocks() {
+		for _, entry := range block.Entries {
+			if prefix, _ := splitRulePrefix(entry.Raw); prefix != "" {
+				continue
+			}
+			if _, ok := plain[entry.Raw]; ok {
+				continue
+			}
+			plain[entry.Raw] = entry
+			order = append(order, entry)
+		}
+	}
+
+	for _, child := range order {
+		domain := child.Raw
+		for {
+			_, parent, ok := strings.Cut(domain, ".")
+			if !ok {
+				break
+			}
+			if p, ok := plain[parent]; ok {
+				ret = append(ret, SubdomainConflictWarning{
+					Parent: p,
+					Child:  child,
+				})
+				break
+			}
+			domain = parent
+		}
+	}
+
+	return ret
+}
+
+// DefaultMaxSuffixesThreshold is the suggested maximum number of
+// entries in one suffix block for ValidateMaxSuffixesPerBlock.
+const DefaultMaxSuffixesThreshold = 1000
+
+// ValidateMaxSuffixesPerBlock reports suffix blocks with more than
+// threshold entries, as TooManySuffixesWarning. Very large blocks can indicate that unrelated
+// suffixes were grouped together under one entity.
+//
+// The threshold is arbitrary, so this check is not run as part of
+// Parse.
+func (f *File) ValidateMaxSuffixesPerBlock(threshold int) []error {
+	var ret []error
+	for _, block := range f.AllSuffixBlocks() {
+		if n := len(block.Entries); n > threshold {
+			ret = append(ret, TooManySuffixesWarning{
+				Suffixes: block,
+				Count:    n,
+			})
+		}
+	}
+	return ret
+}
+
+// DefaultMinSuffixesThreshold is the suggested minimum number of
+// non-exception entries in one suffix block for
+// ValidateMinimumSuffixesPerBlock.
+const DefaultMinSuffixesThreshold = 1
+
+// ValidateMinimumSuffixesPerBlock reports suffix blocks with fewer
+// than min entries that are not exception rules. Such blocks can be
+// the remains of an incomplete submission, or of a cleanup that
+// removed most of a block's suffixes.
+//
+// With the default minimum, this reports blocks that consist only of
+// exception rules. Higher minimums flag small blocks for review, and
+// so this check is not run as part of Parse.
+func (f *File) ValidateMinimumSuffixesPerBlock(min int) []error {
+	var ret []error
+	for _, block := range f.AllSuffixBlocks() {
+		n := 0
+		for _, entry := range block.Entries {
+			if !isException(entry.Raw) {
+				n++
+			}
+		}
+		if n < min {
+			ret = append(ret, TooFewSuffixesWarning{
+				Suffixes: block,
+				Count:    n,
+			})
+		}
+	}
+	return ret
+}
+
+// DetectOverlappingWildcards reports pairs of wildcard suffix entries
+// where the domain of one wildcard is a subdomain of the other, for
+// example "*.example.com" and "*.compute.example.com". Domains such as
+// "foo.compute.example.com" match both wildcards. The PSL algorithm
+// resolves this by preferring the longest rule, but the overlap is
+// often a mistake.
+//
+// A pair is not reported if an exception rule removes the inner
+// wildcard's domain from the outer wildcard's coverage, for example
+// "!compute.example.com" in the example above.
+//
+// This check produces OverlappingWildcardsWarning and is not run as
+// part of Parse.
+func (f *File) DetectOverlappingWildcards() []error {
+	var ret []error
+
+	wildcards := map[string]Source{}
+	var order []Source
+	exceptions := map[string]bool{}
+	for _, block := range f.AllSuffixBlocks() {
+		for _, entry := range block.Entries {
+			prefix, domain := splitRulePrefix(entry.Raw)
+			switch prefix {
+			case "*.":
+				if _, ok := wildcards[domain]; !ok {
+					wildcards[domain] = entry
+					order = append(order, entry)
+				}
+			case "!":
+				exceptions[domain] = true
+			}
+		}
+	}
+
+	for _, inner := range order {
+		_, domain := splitRulePrefix(inner.Raw)
+		for {
+			child := domain
+			_, parent, ok := strings.Cut(domain, ".")
+			if !ok {
+				break
+			}
+			domain = parent
+			outer, ok := wildcards[parent]
+			if !ok || exceptions[child] {
+				continue
+			}
+			ret = append(ret, OverlappingWildcardsWarning{
+				Outer: outer,
+				Inner: inner,
+			})
+		}
+	}
+
+	return ret
+}
+
+// LintWildcardWithNoExceptions reports wildcard suffix entries that
+// have no exception rules anywhere in f, as
+// WildcardWithNoExceptionsWarning. Most wildcards in the PSL exist to
+// carve out exceptions, so a wildcard without any is worth a second
+// look, though it is valid.
+//
+// This check is not run as part of Parse.
+func (f *File) LintWildcardWithNoExceptions() []error {
+	var ret []error
+	for _, w := range f.wildcardExceptions() {
+		if len(w.Exceptions) == 0 {
+			ret = append(ret, WildcardWithNoExceptionsWarning{
+				Wildcard: w.Wildcard,
+			})
+		}
+	}
+	return ret
+}
+
+// DetectUnsortedExceptions reports wildcard suffix entries whose
+// exception rules are not in canonical order: in the same block as
+// the wildcard, after it, and sorted. For example, "*.example.com"
+// should be followed by "!a.example.com" then "!b.example.com".
+//
+// As in the parser's NonContiguousWildcardGroupWarning check, other
+// wildcards and exceptions may appear between a wildcard and its
+// exceptions, so that related wildcards can be listed together
+// followed by all their exceptions. Exceptions separated from their
+// wildcard by an ordinary suffix entry, or listed before it, are
+// moved to directly after the wildcard.
+//
+// Only exceptions in the same block as their wildcard are
+// considered. Each wildcard with misplaced exceptions produces one
+// UnsortedExceptionsWarning. This check is not run as part of Parse.
+func (f *File) DetectUnsortedExceptions() []error {
+	var ret []error
+
+	for _, block := range f.AllSuffixBlocks() {
+		exceptions := map[string][]Source{}
+		for _, entry := range block.Entries {
+			if !isException(entry.Raw) {
+				continue
+			}
+			_, parent, ok := strings.Cut(strings.TrimPrefix(entry.Raw, "!"), ".")
+			if ok {
+				exceptions[parent] = append(exceptions[parent], entry)
+			}
+		}
+
+		for _, entry := range block.Entries {
+			if !isWildcard(entry.Raw) {
+				continue
+			}
+			excs := exceptions[strings.TrimPrefix(entry.Raw, "*.")]
+			if len(excs) == 0 {
+				continue
+			}
+			anchor := entry.Raw
+			if before, ok := exceptionGroupStart(block, entry, excs); ok {
+				anchor = before
+			}
+			if script := sortExceptionsScript(block, anchor, excs); len(script) > 0 {
+				ret = append(ret, UnsortedExceptionsWarning{
+					Wildcard:   entry,
+					Exceptions: excs,
+					EditScript: script,
+				})
+			}
+		}
+	}
+
+	return ret
+}
+
+// exceptionGroupStart reports whether exceptions, the exceptions to
+// wildcard in block, all follow wildcard in its group: after it, with
+// only other wildcards and exceptions in between. If so, it returns
+// the entry directly before the first of exceptions, after which the
+// sorted exceptions belong.
+func exceptionGroupStart(block Suffixes, wildcard Source, exceptions []Source) (string, bool) {
+	w := slices.Index(block.Entries, wildcard)
+	first, last := -1, -1
+	for i, entry := range block.Entries {
+		if !slices.Contains(exceptions, entry) {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+	}
+	if first <= w {
+		return "", false
+	}
+	for _, entry := range block.Entries[w+1 : last] {
+		if !isWildcard(entry.Raw) && !isException(entry.Raw) {
+			return "", false
+		}
+	}
+	return block.Entries[first-1].Raw, true
+}
+
+// sortExceptionsScript returns the moves needed to place exceptions
+// in sorted order directly after the entry anchor, within block. It
+// returns nil if exceptions are already in canonical order.
+func sortExceptionsScript(block Suffixes, anchor string, exceptions []Source) []MoveSuffixEntry {
+	var lines, sorted []string
+	for _, entry := range block.Entries {
+		lines = append(lines, entry.Raw)
+	}
+	for _, exc := range exceptions {
+		sorted = append(sorted, exc.Raw)
+	}
+	slices.Sort(sorted)
+
+	// Place each exception after its predecessor in sorted order,
+	// moving only the exceptions that are not already there.
+	var ret []MoveSuffixEntry
+	prev := anchor
+	for _, exc := range sorted {
+		want := slices.Index(lines, prev) + 1
+		if want >= len(lines) || lines[want] != exc {
+			ret = append(ret, MoveSuffixEntry{
+				Entry:       exc,
+				InsertAfter: prev,
+			})
+			from := slices.Index(lines, exc)
+			lines = slices.Delete(lines, from, from+1)
+			lines = slices.Insert(lines, slices.Index(lines, prev)+1, exc)
+		}
+		prev = exc
+	}
+	return ret
+}
+
+// ValidateCrossBlockDuplicates reports suffixes that are listed in
+// more than one suffix block. Suffixes are compared
+// case-insensitively. Each repeat of a suffix in a later block
+// produces one CrossBlockDuplicateSuffixError, referring back to the
+// first block that lists the suffix.
+//
+// Suffixes repeated within a single block are not reported by this
+// check.
+func (f *File) ValidateCrossBlockDuplicates() []error {
+	var ret []error
+
+	type firstSeen struct {
+		block Suffixes
+		index int // index of block in f.AllSuffixBlocks()
+	}
+	seen := map[string]firstSeen{}
+	for i, block := range f.AllSuffixBlocks() {
+		for _, entry := range block.Entries {
+			suffix := strings.ToLower(entry.Raw)
+			first, ok := seen[suffix]
+			if !ok {
+				seen[suffix] = firstSeen{block, i}
+				continue
+			}
+			if first.index != i {
+				ret = append(ret, CrossBlockDuplicateSuffixError{
+					First:  first.block,
+					Second: block,
+					Suffix: suffix,
+				})
+			}
+		}
+	}
+
+	return ret
+}
+
+// DetectNormalizedDuplicates reports suffix entries that are written
+// differently from an earlier entry, but are identical to it after
+// normalization as done by NormalizeLabels. For example,
+// "公司.example" and "xn--55qx5d.example" are the same suffix in
+// different forms. Such duplicates are easy to miss when comparing
+// entries as plain strings.
+//
+// Entries that are written identically are not reported by this
+// check.
+func (f *File) DetectNormalizedDuplicates() []error {
+	var ret []error
+
+	first := map[string]Source{} // normalized form to first entry
+	spellings := map[string]bool{}
+	for _, block := range f.AllSuffixBlocks() {
+		for _, entry := range block.Entries {
+			normalized, _ := normalizeEntry(entry)
+			prev, ok := first[normalized]
+			if !ok {
+				first[normalized] = entry
+			} else if !spellings[entry.Raw] {
+				ret = append(ret, NormalizedDuplicateError{
+					First:      prev,
+					Second:     entry,
+					Normalized: normalized,
+				})
+			}
+			spellings[entry.Raw] = true
+		}
+	}
+
+	return ret
+}
+
+// registryEntityWords are words in entity names that suggest the
+// entity is a domain registry, for DetectMixedSectionContent.
+var registryEntityWords = []string{
+	"nic",
+	"registry",
+	"registrar",
+	"network information center",
+}
+
+// DetectMixedSectionContent reports private domain suffix blocks
+// that look like they belong in the ICANN section. The heuristics
+// are:
+//
+//   - a single-label entry, such as "uk", is a top-level domain;
+//   - an entity name that mentions a NIC, registry or registrar
+//     suggests a domain registry.
+//
+// Each such block produces one ProbableWrongSectionError, whose
+// Reason names the heuristic that matched. These heuristics have
+// false positives, so this check is not run as part of Parse.
+func (f *File) DetectMixedSectionContent() []error {
+	var ret []error
+
+	for _, block := range f.SuffixBlocksInSection("PRIVATE DOMAINS") {
+		if reason := wrongSectionReason(block); reason != "" {
+			ret = append(ret, ProbableWrongSectionError{
+				Suffixes: block,
+				Reason:   reason,
+			})
+		}
+	}
+
+	return ret
+}
+
+// wrongSectionReason returns the reason that block appears to belong
+// in the ICANN section, or the empty string.
+func wrongSectionReason(block Suffixes) string {
+	for _, entry := range block.Entries {
+		if _, domain := splitRulePrefix(entry.Raw); !strings.Contains(domain, ".") {
+			return fmt.Sprintf("suffix %q is a top-level domain", entry.Raw)
+		}
+	}
+
+	words := " " + strings.ToLower(strings.Join(strings.FieldsFunc(block.Entity, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")) + " "
+	for _, word := range registryEntityWords {
+		if strings.Contains(words, " "+word+" ") {
+			return fmt.Sprintf("entity name mentions %q", word)
+		}
+	}
+
+	return ""
+}
+
+// ValidateNoEmptyComments reports comment blocks that are almost
+// certainly formatting artifacts:
+//
+//   - a comment block whose lines are all empty after removing the
+//     "//" prefix produces an EmptyCommentWarning;
+//   - a single-line comment block with no letters or digits, right
+//     before a block of suffixes, produces a
+//     SingleLineCommentBeforeSuffixBlock instead.
+//
+// This check is not run as part of Parse.
+func (f *File) ValidateNoEmptyComments() []error {
+	var ret []error
+
+	for i, block := range f.Blocks {
+		comment, ok := block.(Comment)
+		if !ok {
+			continue
+		}
+
+		if comment.StartLine == comment.EndLine && i+1 < len(f.Blocks) && !hasMeaningfulText(trimComment(comment.Raw)) {
+			if next, ok := f.Blocks[i+1].(Suffixes); ok {
+				ret = append(ret, SingleLineCommentBeforeSuffixBlock{
+					Comment:  comment,
+					Suffixes: next,
+				})
+				continue
+			}
+		}
+
+		empty := true
+		for _, line := range strings.Split(comment.Raw, "\n") {
+			if trimComment(line) != "" {
+				empty = false
+				break
+			}
+		}
+		if empty {
+			ret = append(ret, EmptyCommentWarning{
+				Comment: comment,
+			})
+		}
+	}
+
+	return ret
+}
+
+// hasMeaningfulText reports whether s contains any letters or digits.
+func hasMeaningfulText(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) >= 0
+}
+
+// DetectEncodingArtifacts reports lines that suggest the file was not
+// clean UTF-8 text before it was parsed. Lines that contain the
+// Unicode replacement character U+FFFD or invalid UTF-8 produce a
+// UnicodeReplacementCharacterError, and lines that contain ASCII
+// control characters other than tab produce a ControlCharacterError.
+// Each line is reported at most once for each kind of problem.
+//
+// This check is not run as part of Parse.
+func (f *File) DetectEncodingArtifacts() []error {
+	var ret []error
+
+	for _, block := range f.Blocks {
+		src := block.source()
+		for i, line := range strings.Split(src.Raw, "\n") {
+			lineSrc := Source{src.StartLine + i, src.StartLine + i, line}
+			if strings.ContainsRune(line, utf8.RuneError) {
+				ret = append(ret, UnicodeReplacementCharacterError{
+					Line: lineSrc,
+				})
+			}
+			if idx := strings.IndexFunc(line, isControlCharacter); idx >= 0 {
+				ret = append(ret, ControlCharacterError{
+					Line: lineSrc,
+					Char: rune(line[idx]),
+				})
+			}
+		}
+	}
+
+	return ret
+}
+
+// isControlCharacter reports whether r is an ASCII control character
+// other than tab.
+func isControlCharacter(r rune) bool {
+	return (r < 0x20 && r != '\t') || r == 0x7f
+}
+
+// ValidateNoTabsInSource reports lines that contain whitespace other
+// than a plain space. The PSL is written with spaces only, so a tab
+// or a Unicode space such as U+00A0 NO-BREAK SPACE is most likely a
+// copy and paste artifact. Parse already trims whitespace from the
+// start and end of lines, so only whitespace within a line is found.
+//
+// Lines that contain a tab produce a TabCharacterError, and lines
+// that contain any other unusual whitespace produce an
+// UnusualWhitespaceError. Each line is reported at most once for each
+// kind of problem.
+//
+// This check is run as part of Parse.
+func (f *File) ValidateNoTabsInSource() []error {
+	var ret []error
+
+	for _, block := range f.Blocks {
+		src := block.source()
+		for i, line := range strings.Split(src.Raw, "\n") {
+			lineSrc := Source{src.StartLine + i, src.StartLine + i, line}
+			if strings.ContainsRune(line, '\t') {
+				ret = append(ret, TabCharacterError{
+					Line: lineSrc,
+				})
+			}
+			if idx := strings.IndexFunc(line, isUnusualWhitespace); idx >= 0 {
+				r, _ := utf8.DecodeRuneInString(line[idx:])
+				ret = append(ret, UnusualWhitespaceError{
+					Line: lineSrc,
+					Char: r,
+				})
+			}
+		}
+	}
+
+	return ret
+}
+
+// isUnusualWhitespace reports whether r is a whitespace character
+// other than space and tab, including zero-width characters that are
+// often mistaken for whitespace.
+func isUnusualWhitespace(r rune) bool {
+	switch r {
+	case ' ', '\t':
+		return false
+	case '\u200b', '\u2060', '\ufeff':
+		// Zero width space, word joiner and zero width no-break
+		// space are not unicode.IsSpace, but are invisible in the
+		// same way.
+		return true
+	}
+	return unicode.IsSpace(r)
+}
+
+// requiredSections are the file sections that every PSL file must
+// have, in the order they appear.
+var requiredSections = []string{"ICANN DOMAINS", "PRIVATE DOMAINS"}
+
+// ValidateSectionCompleteness reports file sections that have no
+// content. Each section with no suffix blocks produces an
+// EmptySectionError, including sections that are never closed, and
+// each of the "ICANN DOMAINS" and "PRIVATE DOMAINS" sections that is
+// absent from f produces a MissingSectionError.
+//
+// PSL fragments, such as test inputs, often omit sections, so this
+// check is not run as part of Parse.
+func (f *File) ValidateSectionCompleteness() []error {
+	var ret []error
+
+	found := map[string]bool{}
+	var (
+		cur      *StartSection
+		suffixes int
+	)
+	endSection := func() {
+		if cur != nil && suffixes == 0 {
+			ret = append(ret, EmptySectionError{
+				Start: *cur,
+			})
+		}
+		cur = nil
+	}
+	for _, block := range f.Blocks {
+		switch v := block.(type) {
+		case StartSection:
+			// A section start without an end for the previous
+			// section implicitly ends it.
+			endSection()
+			cur = &v
+			suffixes = 0
+			found[v.Name] = true
+		case EndSection:
+			endSection()
+		case Suffixes:
+			suffixes++
+		}
+	}
+	endSection()
+
+	for _, name := range requiredSections {
+		if !found[name] {
+			ret = append(ret, MissingSectionError{
+				Name: name,
+			})
+		}
+	}
+
+	return ret
+}
+
+// ValidateTLDFormat reports suffix entries in the ICANN section that
+// are deeper than ICANN suffixes usually are. TLDs such as "uk", and
+// second-level domains such as "co.uk" or "airline.aero", are normal.
+// Suffixes with three or more labels, counting a wildcard as a label,
+// produce an UnusualICANNDepthWarning. Exception rules are not
+// checked.
+//
+// The ICANN section legitimately contains deep suffixes, such as
+// Japanese geographic names, so this check is not run as part of
+// Parse.
+func (f *File) ValidateTLDFormat() []error {
+	var ret []error
+
+	for _, block := range f.SuffixBlocksInSection("ICANN DOMAINS") {
+		for _, entry := range block.Entries {
+			if isException(entry.Raw) {
+				continue
+			}
+			if depth := strings.Count(entry.Raw, ".") + 1; depth >= 3 {
+				ret = append(ret, UnusualICANNDepthWarning{
+					Line:  entry,
+					Depth: depth,
+				})
+			}
+		}
+	}
+
+	return ret
+}
+
+// DetectSuffixesOverlappingIANATLDs reports private domain suffix
+// entries that cover an entire top-level domain from ianaList, such
+// as "com" or "*.com". ianaList is the list of delegated TLDs, for
+// example from https://data.iana.org/TLD/tlds-alpha-by-domain.txt,
+// and is compared case-insensitively. Exception rules are not
+// checked.
+//
+// Each such entry produces a PrivateSuffixMasksIANATLDError. This
+// check is not run as part of Parse, since it needs the IANA list.
+func (f *File) DetectSuffixesOverlappingIANATLDs(ianaList []string) []error {
+	var ret []error
+
+	tlds := map[string]bool{}
+	for _, tld := range ianaList {
+		tlds[strings.ToLower(strings.TrimSpace(tld))] = true
+	}
+
+	for _, block := range f.SuffixBlocksInSection("PRIVATE DOMAINS") {
+		for _, entry := range block.Entries {
+			prefix, domain := splitRulePrefix(strings.ToLower(entry.Raw))
+			if prefix == "!" || !tlds[domain] {
+				continue
+			}
+			ret = append(ret, PrivateSuffixMasksIANATLDError{
+				Suffixes: block,
+				Line:     entry,
+				TLD:      domain,
+			})
+		}
+	}
+
+	return ret
+}
+
+// RequireEntities reports each of names that is not the entity name
+// of any suffix block in f, compared case-insensitively, as a
+// MissingRequiredEntityError. This is a policy check for PSL files
+// that must always contain certain entities, and is not run as part
+// of Parse.
+func (f *File) RequireEntities(names []string) []error {
+	var ret []error
+
+	found := map[string]bool{}
+	for _, block := range f.AllSuffixBlocks() {
+		found[strings.ToLower(block.Entity)] = true
+	}
+	for _, name := range names {
+		if !found[strings.ToLower(name)] {
+			ret = append(ret, MissingRequiredEntityError{
+				Name: name,
+			})
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,1291 @@
+package parser
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	diff "github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/net/idna"
+)
+
+func TestValidatePrivateBeforeICANN(t *testing.T) {
+	tests := []struct {
+		name string
+		psl  string
+		want error
+	}{
+		{
+			name: "ok",
+			psl: dedent(`
+              // ===BEGIN ICANN DOMAINS===
+              // ===END ICANN DOMAINS===
+              // ===BEGIN PRIVATE DOMAINS===
+              // ===END PRIVATE DOMAINS===
+            `),
+			want: nil,
+		},
+		{
+			name: "out_of_order",
+			psl: dedent(`
+              // ===BEGIN PRIVATE DOMAINS===
+              // ===END PRIVATE DOMAINS===
+              // ===BEGIN ICANN DOMAINS===
+              // ===END ICANN DOMAINS===
+            `),
+			want: SectionOutOfOrderError{
+				ICANN: StartSection{
+					Source: src(3, 3, "// ===BEGIN ICANN DOMAINS==="),
+					Name:   "ICANN DOMAINS",
+				},
+				Private: StartSection{
+					Source: src(1, 1, "// ===BEGIN PRIVATE DOMAINS==="),
+					Name:   "PRIVATE DOMAINS",
+				},
+			},
+		},
+		{
+			name: "missing_icann",
+			psl: dedent(`
+              // ===BEGIN PRIVATE DOMAINS===
+              // ===END PRIVATE DOMAINS===
+            `),
+			want: MissingSectionError{Name: "ICANN DOMAINS"},
+		},
+		{
+			name: "missing_private",
+			psl: dedent(`
+              // ===BEGIN ICANN DOMAINS===
+              // ===END ICANN DOMAINS===
+            `),
+			want: MissingSectionError{Name: "PRIVATE DOMAINS"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := Parse(test.psl)
+			got := f.ValidatePrivateBeforeICANN()
+			if diff := diff.Diff(test.want, got); diff != "" {
+				t.Errorf("unexpected validation result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDetectTooShortEntities(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // cd
+      cd
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // A : https://a.example
+      // Submitted by A <a@example.com>
+      a.example
+
+      // 12345 : https://numbers.example
+      // Submitted by Numbers <n@example.com>
+      numbers.example
+
+      // ... : https://dots.example
+      // Submitted by Dots <d@example.com>
+      dots.example
+
+      // 公司 : https://gongsi.example
+      // Submitted by Gongsi <g@example.com>
+      gongsi.example
+
+      // DuckCorp Inc : https://duck.example
+      // Submitted by Duck <duck@example.com>
+      duck.example
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	blocks := f.AllSuffixBlocks()
+	want := []error{
+		SuspiciouslyShortEntityWarning{Suffixes: blocks[1]},
+		NonAlphanumericEntityWarning{Suffixes: blocks[2]},
+		NonAlphanumericEntityWarning{Suffixes: blocks[3]},
+		SuspiciouslyShortEntityWarning{Suffixes: blocks[4]},
+	}
+	got := f.DetectTooShortEntities(DefaultMinEntityNameLength)
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestDetectURLDomainMismatches(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // com
+      com
+
+      // uk
+      uk
+      co.uk
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Matching Corp : https://www.matching.com/about
+      // Submitted by Matching <m@matching.com>
+      users.other.com
+      apps.matching.com
+
+      // Matching Ltd : https://matching.co.uk
+      // Submitted by Matching <m@matching.co.uk>
+      *.matching.co.uk
+
+      // Copy Paste Corp : https://www.matching.com
+      // Submitted by Copy Paste <cp@copypaste.com>
+      copypaste.com
+
+      // No URL Corp
+      // Submitted by No URL <nu@nourl.com>
+      nourl.com
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	want := []error{
+		URLDomainMismatchWarning{
+			Suffixes:  f.AllSuffixBlocks()[4],
+			URLDomain: "matching.com",
+		},
+	}
+	got := f.DetectURLDomainMismatches()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestDetectEmailDomainMismatches(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // com
+      com
+
+      // uk
+      uk
+      co.uk
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Matching Corp : https://www.matching.com/about
+      // Submitted by Matching <m@mail.matching.com>
+      apps.matching.com
+
+      // Website Corp : https://website.co.uk
+      // Submitted by Website <w@website.co.uk>
+      *.website-users.com
+
+      // Agency Client Corp : https://client.com
+      // Submitted by Agency <client@agency.com>
+      client.com
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	want := []error{
+		EmailDomainMismatchWarning{
+			Suffixes:    f.AllSuffixBlocks()[4],
+			Line:        src(22, 22, "// Submitted by Agency <client@agency.com>"),
+			EmailDomain: "agency.com",
+		},
+	}
+	got := f.DetectEmailDomainMismatches()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestDetectMalformedSectionMarkers(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      //===BEGIN NOSPACE DOMAINS===
+      // ==BEGIN SHORT DOMAINS==
+      // ==END LONG DOMAINS===
+      // === BEGIN  SPACEY DOMAINS ===
+      // ===begin LOWER DOMAINS===
+
+      // Just a comment.
+      // ===================
+
+      // ===END ICANN DOMAINS===
+    `))
+
+	want := []error{
+		MalformedSectionMarker{
+			Line:       src(3, 3, "//===BEGIN NOSPACE DOMAINS==="),
+			Suggestion: "// ===BEGIN NOSPACE DOMAINS===",
+		},
+		MalformedSectionMarker{
+			Line:       src(4, 4, "// ==BEGIN SHORT DOMAINS=="),
+			Suggestion: "// ===BEGIN SHORT DOMAINS===",
+		},
+		MalformedSectionMarker{
+			Line:       src(5, 5, "// ==END LONG DOMAINS==="),
+			Suggestion: "// ===END LONG DOMAINS===",
+		},
+		MalformedSectionMarker{
+			Line:       src(6, 6, "// === BEGIN  SPACEY DOMAINS ==="),
+			Suggestion: "// ===BEGIN SPACEY DOMAINS===",
+		},
+		MalformedSectionMarker{
+			Line:       src(7, 7, "// ===begin LOWER DOMAINS==="),
+			Suggestion: "// ===BEGIN LOWER DOMAINS===",
+		},
+	}
+	got := f.DetectMalformedSectionMarkers()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateNoAllCapsEntities(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // com
+      com
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // COMPANY NAME : https://shouty.example
+      // Submitted by Shouty <s@example.com>
+      shouty.example
+
+      // company name : https://quiet.example
+      // Submitted by Quiet <q@example.com>
+      quiet.example
+
+      // Company Name 2 : https://proper.example
+      // Submitted by Proper <p@example.com>
+      proper.example
+
+      // 公司 : https://gongsi.example
+      // Submitted by Gongsi <g@example.com>
+      gongsi.example
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	blocks := f.AllSuffixBlocks()
+	want := []error{
+		AllCapsEntityNameWarning{Suffixes: blocks[1]},
+		AllLowerCaseEntityNameWarning{Suffixes: blocks[2]},
+	}
+	got := f.ValidateNoAllCapsEntities()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestDetectSubdomainConflicts(t *testing.T) {
+	f := Parse(dedent(`
+      // example
+      example
+      *.wild.example
+      !www.wild.example
+      foo.bar.example
+
+      // Other Corp
+      bar.example
+      baz.foo.bar.example
+      unrelated.test
+    `))
+
+	want := []error{
+		SubdomainConflictWarning{
+			Parent: src(8, 8, "bar.example"),
+			Child:  src(5, 5, "foo.bar.example"),
+		},
+		SubdomainConflictWarning{
+			Parent: src(2, 2, "example"),
+			Child:  src(8, 8, "bar.example"),
+		},
+		SubdomainConflictWarning{
+			Parent: src(5, 5, "foo.bar.example"),
+			Child:  src(9, 9, "baz.foo.bar.example"),
+		},
+	}
+	got := f.DetectSubdomainConflicts()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateMaxSuffixesPerBlock(t *testing.T) {
+	f := Parse(dedent(`
+      // Small Corp
+      a.small.example
+      b.small.example
+
+      // Big Corp
+      a.big.example
+      b.big.example
+      c.big.example
+    `))
+
+	want := []error{
+		TooManySuffixesWarning{
+			Suffixes: f.AllSuffixBlocks()[1],
+			Count:    3,
+		},
+	}
+	got := f.ValidateMaxSuffixesPerBlock(2)
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+
+	if errs := f.ValidateMaxSuffixesPerBlock(DefaultMaxSuffixesThreshold); len(errs) > 0 {
+		t.Errorf("unexpected errors with default threshold: %v", errs)
+	}
+}
+
+func TestValidateNoSubpathURLs(t *testing.T) {
+	f := Parse(dedent(`
+      // Root Corp : https://root.example
+      root.example
+
+      // Shallow Corp : https://shallow.example/about/psl/
+      shallow.example
+
+      // Deep Corp : https://deep.example/internal/psl-stuff/entry#anchor
+      deep.example
+
+      // No URL Corp
+      nourl.example
+    `))
+
+	want := []error{
+		DeepPathURLWarning{
+			Suffixes: f.AllSuffixBlocks()[2],
+			URL:      mustURL("https://deep.example/internal/psl-stuff/entry#anchor"),
+		},
+	}
+	got := f.ValidateNoSubpathURLs()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestDetectSharedURLs(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // example : https://www.iana.org/domains/root/db/example.html
+      example
+
+      // other : https://www.iana.org/domains/root/db/example.html
+      other
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Acme Corp : https://acme.example/
+      // Submitted by Acme <a@acme.example>
+      acme.example
+
+      // Acme Corporation : http://www.ACME.example
+      // Submitted by Acme <a@acme.example>
+      acme2.example
+
+      // Same Name : https://same.example
+      // Submitted by Same <s@same.example>
+      same.example
+
+      // Same Name : https://same.example
+      // Submitted by Same <s@same.example>
+      same2.example
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	blocks := f.AllSuffixBlocks()
+	want := []error{
+		SharedURLDifferentEntityWarning{
+			URL:    "acme.example",
+			Blocks: []Suffixes{blocks[2], blocks[3]},
+		},
+	}
+	got := f.DetectSharedURLs()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateMinimumSuffixesPerBlock(t *testing.T) {
+	f := Parse(dedent(`
+      // Exceptions Corp
+      !www.exceptions.example
+
+      // Single Corp
+      single.example
+      !www.single.example
+
+      // Pair Corp
+      a.pair.example
+      b.pair.example
+    `))
+
+	blocks := f.AllSuffixBlocks()
+	exceptionsOnly := TooFewSuffixesWarning{Suffixes: blocks[0], Count: 0}
+	single := TooFewSuffixesWarning{Suffixes: blocks[1], Count: 1}
+
+	tests := []struct {
+		min  int
+		want []error
+	}{
+		{DefaultMinSuffixesThreshold, []error{exceptionsOnly}},
+		{2, []error{exceptionsOnly, single}},
+	}
+	for _, test := range tests {
+		got := f.ValidateMinimumSuffixesPerBlock(test.min)
+		if diff := diff.Diff(test.want, got); diff != "" {
+			t.Errorf("ValidateMinimumSuffixesPerBlock(%d) wrong result (-want +got):\n%s", test.min, diff)
+		}
+	}
+}
+
+func TestDetectNearDuplicateEntities(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // com
+      com
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // GitHub : https://github.com
+      // Submitted by GitHub <gh@github.com>
+      github.io
+
+      // Github : https://github.com
+      // Submitted by GitHub <gh@github.com>
+      githubusercontent.com
+
+      // GitHub : https://github.com
+      // Submitted by GitHub <gh@github.com>
+      github.dev
+
+      // Duck Corp : https://duck.example
+      // Submitted by Duck <d@duck.example>
+      duck.example
+
+      // DuckCorp : https://duck.example
+      // Submitted by Duck <d@duck.example>
+      duck2.example
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	blocks := f.AllSuffixBlocks()
+	want := []error{
+		DuplicateEntityWarning{First: blocks[1], Second: blocks[2]},
+		DuplicateEntityWarning{First: blocks[4], Second: blocks[5]},
+	}
+	got := f.DetectNearDuplicateEntities()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateEmailNotRole(t *testing.T) {
+	f := Parse(dedent(`
+      // Info Corp
+      // Submitted by Info <Info@info.example>
+      info.example
+
+      // Support Corp
+      // Submitted by Support <support-psl@support.example>
+      support.example
+
+      // Person Corp
+      // Submitted by Jane Doe <jane@person.example>
+      person.example
+
+      // Prefix Corp
+      // Submitted by Informed <informed@prefix.example>
+      prefix.example
+
+      // Nobody Corp
+      nobody.example
+    `))
+
+	blocks := f.AllSuffixBlocks()
+	want := []error{
+		RoleEmailAddressWarning{
+			Suffixes: blocks[0],
+			Address:  mustEmail("Info <Info@info.example>"),
+		},
+		RoleEmailAddressWarning{
+			Suffixes: blocks[1],
+			Address:  mustEmail("Support <support-psl@support.example>"),
+		},
+	}
+	got := f.ValidateEmailNotRole(DefaultRoleEmailPrefixes)
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+	}
+
+	want = []error{
+		RoleEmailAddressWarning{
+			Suffixes: blocks[2],
+			Address:  mustEmail("Jane Doe <jane@person.example>"),
+		},
+	}
+	got = f.ValidateEmailNotRole([]string{"jane"})
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected warnings with custom prefixes (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateOrdering(t *testing.T) {
+	sorted := dedent(`
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Alpha Corp
+      alpha.example
+
+      // beta corp
+      beta.example
+
+      // Gamma Corp
+      gamma.example
+
+      // ===END PRIVATE DOMAINS===
+    `)
+	if err := Parse(sorted).ValidateOrdering(); err != nil {
+		t.Errorf("ValidateOrdering() on sorted file = %v, want nil", err)
+	}
+
+	f := Parse(dedent(`
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Alpha Corp
+      alpha.example
+
+      // Zulu Corp
+      zulu.example
+
+      // Beta Corp
+      beta.example
+
+      // Gamma Corp
+      gamma.example
+
+      // Aardvark Corp
+      aardvark.example
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	err := f.ValidateOrdering()
+	v, ok := err.(SuffixBlocksInWrongPlace)
+	if !ok {
+		t.Fatalf("ValidateOrdering() = %v, want SuffixBlocksInWrongPlace", err)
+	}
+	want := []string{"Zulu Corp", "Aardvark Corp"}
+	if diff := diff.Diff(want, entities(v.Blocks)); diff != "" {
+		t.Errorf("unexpected misplaced blocks (-want +got):\n%s", diff)
+	}
+
+	bs, err := json.Marshal(v.EditScript)
+	if err != nil {
+		t.Fatalf("marshaling edit script: %v", err)
+	}
+	wantJSON := `[{"name":"Zulu Corp","insert_after":"Gamma Corp"},{"name":"Aardvark Corp"}]`
+	if diff := diff.Diff(wantJSON, string(bs)); diff != "" {
+		t.Errorf("unexpected edit script (-want +got):\n%s", diff)
+	}
+
+	// Two misplaced blocks that belong after the same sorted block
+	// must be placed relative to each other.
+	f = Parse(dedent(`
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Charlie Corp
+      charlie.example
+
+      // Delta Corp
+      delta.example
+
+      // Alpha Corp
+      alpha.example
+
+      // Bravo Corp
+      bravo.example
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	v, ok = f.ValidateOrdering().(SuffixBlocksInWrongPlace)
+	if !ok {
+		t.Fatalf("ValidateOrdering() did not return SuffixBlocksInWrongPlace")
+	}
+	wantScript := []MoveSuffixBlock{
+		{Name: "Charlie Corp", InsertAfter: "Bravo Corp"},
+		{Name: "Delta Corp", InsertAfter: "Charlie Corp"},
+	}
+	if diff := diff.Diff(wantScript, v.EditScript); diff != "" {
+		t.Errorf("unexpected edit script (-want +got):\n%s", diff)
+	}
+	got := applyMoveSuffixBlocks(entities(f.AllSuffixBlocks()), v.EditScript)
+	wantOrder := []string{"Alpha Corp", "Bravo Corp", "Charlie Corp", "Delta Corp"}
+	if diff := diff.Diff(wantOrder, got); diff != "" {
+		t.Errorf("applying edit script gave wrong order (-want +got):\n%s", diff)
+	}
+}
+
+// applyMoveSuffixBlocks returns the result of applying script to the
+// list of entity names names, in order.
+func applyMoveSuffixBlocks(names []string, script []MoveSuffixBlock) []string {
+	names = slices.Clone(names)
+	for _, move := range script {
+		names = slices.DeleteFunc(names, func(n string) bool { return n == move.Name })
+		at := slices.Index(names, move.InsertAfter) + 1
+		names = slices.Insert(names, at, move.Name)
+	}
+	return names
+}
+
+func TestValidateIDNARegistration(t *testing.T) {
+	f := Parse(dedent(`
+      // Example Corp
+      公司.example
+      xn--55qx5d.example
+      *.über.example
+      ｆoo.example
+      bÄr.example
+      xn--br-4fa.example
+    `))
+
+	idnaErr := func(label string) error {
+		_, err := idna.Registration.ToASCII(label)
+		return err
+	}
+	want := []error{
+		DisallowedIDNACharacterError{
+			Line:      src(5, 5, "ｆoo.example"),
+			Label:     "ｆoo",
+			CodePoint: 'ｆ',
+			Err:       idnaErr("ｆoo"),
+		},
+		DisallowedIDNACharacterError{
+			Line:      src(6, 6, "bÄr.example"),
+			Label:     "bÄr",
+			CodePoint: 'Ä',
+			Err:       idnaErr("bÄr"),
+		},
+		DisallowedIDNACharacterError{
+			Line:      src(7, 7, "xn--br-4fa.example"),
+			Label:     "xn--br-4fa",
+			CodePoint: 'Ä',
+			Err:       idnaErr("bÄr"),
+		},
+	}
+	got := f.ValidateIDNARegistration()
+	if diff := diff.Diff(want, got, cmpopts.EquateErrors()); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateNonASCIIEntities(t *testing.T) {
+	f := Parse(dedent(`
+      // ASCII Corp
+      ascii.example
+
+      // 公司
+      gongsi.example
+
+      // Café Composed
+      composed.example
+    `) + "\n\n// Cafe\u0301 Decomposed\ndecomposed.example")
+
+	blocks := f.AllSuffixBlocks()
+	wantDetect := blocks[1:]
+	if diff := diff.Diff(wantDetect, f.DetectNonASCIIEntities()); diff != "" {
+		t.Errorf("DetectNonASCIIEntities() wrong result (-want +got):\n%s", diff)
+	}
+
+	want := []error{
+		NonNormalizedEntityNameError{Suffixes: blocks[3]},
+	}
+	got := f.ValidateNonASCIIEntities()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestDetectOverlappingWildcards(t *testing.T) {
+	f := Parse(dedent(`
+      // Example Corp
+      *.example.com
+      *.compute.example.com
+      *.a.b.compute.example.com
+      *.excepted.example.com
+      !excepted.example.com
+      *.other.com
+    `))
+
+	want := []error{
+		OverlappingWildcardsWarning{
+			Outer: src(2, 2, "*.example.com"),
+			Inner: src(3, 3, "*.compute.example.com"),
+		},
+		OverlappingWildcardsWarning{
+			Outer: src(3, 3, "*.compute.example.com"),
+			Inner: src(4, 4, "*.a.b.compute.example.com"),
+		},
+		OverlappingWildcardsWarning{
+			Outer: src(2, 2, "*.example.com"),
+			Inner: src(4, 4, "*.a.b.compute.example.com"),
+		},
+	}
+	got := f.DetectOverlappingWildcards()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected warnings (-want +got):\n%s", diff)
+	}
+}
+
+func TestLintWildcardWithNoExceptions(t *testing.T) {
+	f := Parse(dedent(`
+      // Wildcard Corp
+      *.wild.example
+      !www.wild.example
+      *.lonely.example
+      *.other.example
+
+      // Faraway Exception Corp
+      !www.other.example
+    `))
+
+	want := []error{
+		WildcardWithNoExceptionsWarning{
+			Wildcard: src(4, 4, "*.lonely.example"),
+		},
+	}
+	got := f.LintWildcardWithNoExceptions()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateCrossBlockDuplicates(t *testing.T) {
+	f := Parse(dedent(`
+      // First Corp
+      first.example
+      shared.example
+      twice.example
+      twice.example
+
+      // Second Corp
+      Shared.example
+      second.example
+
+      // Third Corp
+      shared.example
+    `))
+
+	blocks := f.AllSuffixBlocks()
+	want := []error{
+		CrossBlockDuplicateSuffixError{
+			First:  blocks[0],
+			Second: blocks[1],
+			Suffix: "shared.example",
+		},
+		CrossBlockDuplicateSuffixError{
+			First:  blocks[0],
+			Second: blocks[2],
+			Suffix: "shared.example",
+		},
+	}
+	got := f.ValidateCrossBlockDuplicates()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestDetectNormalizedDuplicates(t *testing.T) {
+	f := Parse(dedent(`
+      // Example Corp
+      公司.example
+      other.example
+      xn--55qx5d.example
+      xn--55qx5d.example
+      *.Wild.example
+
+      // Other Corp
+      *.wild.EXAMPLE
+      other.example
+    `))
+
+	want := []error{
+		NormalizedDuplicateError{
+			First:      src(2, 2, "公司.example"),
+			Second:     src(4, 4, "xn--55qx5d.example"),
+			Normalized: "xn--55qx5d.example",
+		},
+		NormalizedDuplicateError{
+			First:      src(6, 6, "*.Wild.example"),
+			Second:     src(9, 9, "*.wild.EXAMPLE"),
+			Normalized: "*.wild.example",
+		},
+	}
+	got := f.DetectNormalizedDuplicates()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestDetectMixedSectionContent(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // uk : Nominet UK Registry
+      uk
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Misfiled Corp
+      zz
+
+      // Example NIC, Inc.
+      nic.example
+
+      // Network Information Center of Example
+      nic2.example
+
+      // Nicholas Corp
+      nicholas.example
+
+      // Ordinary Corp
+      ordinary.example
+
+      // ===END PRIVATE DOMAINS===
+    `))
+
+	blocks := f.AllSuffixBlocks()
+	want := []error{
+		ProbableWrongSectionError{
+			Suffixes: blocks[1],
+			Reason:   `suffix "zz" is a top-level domain`,
+		},
+		ProbableWrongSectionError{
+			Suffixes: blocks[2],
+			Reason:   `entity name mentions "nic"`,
+		},
+		ProbableWrongSectionError{
+			Suffixes: blocks[3],
+			Reason:   `entity name mentions "network information center"`,
+		},
+	}
+	got := f.DetectMixedSectionContent()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateNoEmptyComments(t *testing.T) {
+	f := Parse(dedent(`
+      // Real comment.
+
+      //
+      //
+
+      // -
+      // Example Corp
+      example.com
+
+      // -
+
+      // Other Corp
+      other.example
+
+      //
+
+      // Meaningful note
+    `))
+
+	want := []error{
+		EmptyCommentWarning{
+			Comment: Comment{Source: src(3, 4, "//\n//")},
+		},
+		SingleLineCommentBeforeSuffixBlock{
+			Comment: Comment{Source: src(10, 10, "// -")},
+			Suffixes: Suffixes{
+				Source:  src(12, 13, "// Other Corp\nother.example"),
+				Header:  []Source{src(12, 12, "// Other Corp")},
+				Entries: []Source{src(13, 13, "other.example")},
+				Entity:  "Other Corp",
+			},
+		},
+		EmptyCommentWarning{
+			Comment: Comment{Source: src(15, 15, "//")},
+		},
+	}
+	got := f.ValidateNoEmptyComments()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestDetectUnsortedExceptions(t *testing.T) {
+	f := Parse(dedent(`
+      // Sorted Corp
+      *.sorted.example
+      !a.sorted.example
+      !b.sorted.example
+
+      // Unsorted Corp
+      *.unsorted.example
+      !b.unsorted.example
+      !a.unsorted.example
+
+      // Far Corp
+      *.far.example
+      other.example
+      !www.far.example
+
+      // Elsewhere Corp
+      !www.elsewhere.example
+    `) + "\n\n" + dedent(`
+      // Wildcard Corp
+      *.elsewhere.example
+
+      // jp
+      *.kawasaki.jp
+      *.kitakyushu.jp
+      *.kobe.jp
+      !city.kawasaki.jp
+      !city.kitakyushu.jp
+      !city.kobe.jp
+
+      // Grouped Corp
+      *.one.example
+      *.two.example
+      !b.one.example
+      !a.one.example
+      !a.two.example
+    `))
+
+	want := []error{
+		UnsortedExceptionsWarning{
+			Wildcard: src(7, 7, "*.unsorted.example"),
+			Exceptions: []Source{
+				src(8, 8, "!b.unsorted.example"),
+				src(9, 9, "!a.unsorted.example"),
+			},
+			EditScript: []MoveSuffixEntry{
+				{Entry: "!a.unsorted.example", InsertAfter: "*.unsorted.example"},
+			},
+		},
+		UnsortedExceptionsWarning{
+			Wildcard: src(12, 12, "*.far.example"),
+			Exceptions: []Source{
+				src(14, 14, "!www.far.example"),
+			},
+			EditScript: []MoveSuffixEntry{
+				{Entry: "!www.far.example", InsertAfter: "*.far.example"},
+			},
+		},
+		UnsortedExceptionsWarning{
+			Wildcard: src(31, 31, "*.one.example"),
+			Exceptions: []Source{
+				src(33, 33, "!b.one.example"),
+				src(34, 34, "!a.one.example"),
+			},
+			EditScript: []MoveSuffixEntry{
+				{Entry: "!a.one.example", InsertAfter: "*.two.example"},
+			},
+		},
+	}
+	got := f.DetectUnsortedExceptions()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestDetectEncodingArtifacts(t *testing.T) {
+	f := Parse("// Clean Corp\n" +
+		"clean.example\n" +
+		"\n" +
+		"// Mojibake Corp \ufffd\n" +
+		"mojibake.example\n" +
+		"// Tab\tand bell\a\n" +
+		"bad\xffutf8.example\n")
+
+	want := []error{
+		UnicodeReplacementCharacterError{
+			Line: src(4, 4, "// Mojibake Corp \ufffd"),
+		},
+		ControlCharacterError{
+			Line: src(6, 6, "// Tab\tand bell\a"),
+			Char: '\a',
+		},
+		UnicodeReplacementCharacterError{
+			Line: src(7, 7, "bad\xffutf8.example"),
+		},
+	}
+	got := f.DetectEncodingArtifacts()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateNoTabsInSource(t *testing.T) {
+	f := Parse("// Clean Corp\n" +
+		"clean.example\n" +
+		"\n" +
+		"// Tab\tCorp\n" +
+		"tab.example\n" +
+		"// Pasted\u00a0from a web page\n" +
+		"nbsp.example\n" +
+		"// Both\tkinds\u3000here\n" +
+		"zero\u200bwidth.example\n")
+
+	want := []error{
+		TabCharacterError{
+			Line: src(4, 4, "// Tab\tCorp"),
+		},
+		UnusualWhitespaceError{
+			Line: src(6, 6, "// Pasted\u00a0from a web page"),
+			Char: '\u00a0',
+		},
+		TabCharacterError{
+			Line: src(8, 8, "// Both\tkinds\u3000here"),
+		},
+		UnusualWhitespaceError{
+			Line: src(8, 8, "// Both\tkinds\u3000here"),
+			Char: '\u3000',
+		},
+		UnusualWhitespaceError{
+			Line: src(9, 9, "zero\u200bwidth.example"),
+			Char: '\u200b',
+		},
+	}
+	got := f.ValidateNoTabsInSource()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateSectionCompleteness(t *testing.T) {
+	f := Parse(testFile)
+	if errs := f.ValidateSectionCompleteness(); len(errs) > 0 {
+		t.Errorf("unexpected errors for complete file: %v", errs)
+	}
+
+	f = Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // Only a comment.
+
+      // ===END ICANN DOMAINS===
+    `))
+	want := []error{
+		EmptySectionError{
+			Start: StartSection{
+				Source: src(1, 1, "// ===BEGIN ICANN DOMAINS==="),
+				Name:   "ICANN DOMAINS",
+			},
+		},
+		MissingSectionError{Name: "PRIVATE DOMAINS"},
+	}
+	got := f.ValidateSectionCompleteness()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+
+	// Sections that are never closed are also checked.
+	f = Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // example
+      example
+
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Only a comment.
+    `))
+	want = []error{
+		EmptySectionError{
+			Start: StartSection{
+				Source: src(6, 6, "// ===BEGIN PRIVATE DOMAINS==="),
+				Name:   "PRIVATE DOMAINS",
+			},
+		},
+	}
+	got = f.ValidateSectionCompleteness()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors for unclosed sections (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateAllEmailsHaveDisplayNames(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // example : https://example.org
+      // Submitted by <registry@example.org>
+      example
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Named Corp
+      // Submitted by Jane Doe <jane@named.example>
+      named.example
+
+      // Anonymous Corp
+      // Submitted by <admin@anonymous.example>
+      anonymous.example
+
+      // ===END PRIVATE DOMAINS===
+    `))
+
+	blocks := f.AllSuffixBlocks()
+	wantParse := []error{
+		EmailMissingDisplayNameError{
+			Suffixes: blocks[2],
+			Address:  mustEmail("<admin@anonymous.example>"),
+		},
+	}
+	if diff := diff.Diff(wantParse, f.Errors); diff != "" {
+		t.Errorf("unexpected parse errors (-want +got):\n%s", diff)
+	}
+
+	want := []error{
+		EmailMissingDisplayNameWarning{
+			Suffixes: blocks[0],
+			Address:  mustEmail("<registry@example.org>"),
+		},
+		EmailMissingDisplayNameError{
+			Suffixes: blocks[2],
+			Address:  mustEmail("<admin@anonymous.example>"),
+		},
+	}
+	got := f.ValidateAllEmailsHaveDisplayNames()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateTLDFormat(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // jp
+      jp
+      co.jp
+      city.example.jp
+      *.wild.jp
+      !www.wild.jp
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Private Corp
+      // Submitted by Private <private@example.com>
+      deep.private.example.com
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	want := []error{
+		UnusualICANNDepthWarning{Line: src(6, 6, "city.example.jp"), Depth: 3},
+		UnusualICANNDepthWarning{Line: src(7, 7, "*.wild.jp"), Depth: 3},
+	}
+	got := f.ValidateTLDFormat()
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestDetectSuffixesOverlappingIANATLDs(t *testing.T) {
+	f := Parse(dedent(`
+      // ===BEGIN ICANN DOMAINS===
+
+      // com
+      com
+
+      // ===END ICANN DOMAINS===
+      // ===BEGIN PRIVATE DOMAINS===
+
+      // Greedy Corp
+      // Submitted by Greedy <greedy@example.com>
+      COM
+      *.net
+      !www.net
+      fine.org
+      notatld
+
+      // ===END PRIVATE DOMAINS===
+    `))
+	if len(f.Errors) > 0 {
+		t.Fatalf("unexpected parse errors: %v", f.Errors)
+	}
+
+	greedy := f.AllSuffixBlocks()[1]
+	want := []error{
+		PrivateSuffixMasksIANATLDError{
+			Suffixes: greedy,
+			Line:     src(11, 11, "COM"),
+			TLD:      "com",
+		},
+		PrivateSuffixMasksIANATLDError{
+			Suffixes: greedy,
+			Line:     src(12, 12, "*.net"),
+			TLD:      "net",
+		},
+	}
+	got := f.DetectSuffixesOverlappingIANATLDs([]string{"COM", "NET", "ORG"})
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}
+
+func TestRequireEntities(t *testing.T) {
+	f := Parse(testFile)
+
+	want := []error{
+		MissingRequiredEntityError{Name: "Missing Corp"},
+	}
+	got := f.RequireEntities([]string{"duckcorp inc", "AWS Thing", "Missing Corp"})
+	if diff := diff.Diff(want, got); diff != "" {
+		t.Errorf("unexpected errors (-want +got):\n%s", diff)
+	}
+}